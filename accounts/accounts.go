@@ -0,0 +1,233 @@
+package accounts
+
+import (
+	"errors"
+	"github.com/terorie/go-nimiq/core"
+	"github.com/terorie/go-nimiq/policy"
+	"github.com/terorie/go-nimiq/storage"
+)
+
+// Account states of a chain
+type Accounts struct {
+	Tree *Tree
+}
+
+var (
+	ErrAccountsHashMismatch = errors.New("accounts hash does not match block")
+	ErrLightBlock = errors.New("block has no body")
+	ErrInvalidPrunedAccount = errors.New("pruned account does not match state")
+	ErrMissingPrunedAccount = errors.New("empty contract not pruned by block")
+)
+
+func NewAccounts() *Accounts {
+	return &Accounts{ Tree: NewTree() }
+}
+
+// Loads the accounts saved by Tree.Save or
+// the batches of Commit and Revert
+func LoadAccounts(r storage.Reader) (*Accounts, error) {
+	tree, err := LoadTree(r)
+	if err != nil { return nil, err }
+	return &Accounts{ Tree: tree }, nil
+}
+
+// Returns the account at address,
+// InitialAccount if there is none
+func (a *Accounts) Get(address *core.Address) core.Account {
+	return getAccount(a.Tree, address)
+}
+
+func (a *Accounts) Hash() core.Hash {
+	return a.Tree.RootHash()
+}
+
+// Applies the block to the accounts.
+// The resulting hash must match the accounts hash of the block.
+// The changed nodes are added to b if not nil (see commitTx).
+// On error, the accounts are left unchanged.
+func (a *Accounts) Commit(block *core.Block, txCache core.TxCache, b *storage.Batch) error {
+	if block.Body == nil {
+		return ErrLightBlock
+	}
+
+	tx := a.Tree.Transaction()
+
+	err := commitBody(tx, block.Body, block.Header.Height, txCache)
+	if err != nil { return err }
+
+	if tx.RootHash() != block.Header.AccountsHash {
+		return ErrAccountsHashMismatch
+	}
+
+	a.commitTx(tx, b)
+	return nil
+}
+
+// Undoes the block, it must be the last committed block.
+// The changed nodes are added to b if not nil (see commitTx).
+// On error, the accounts are left unchanged.
+func (a *Accounts) Revert(block *core.Block, b *storage.Batch) error {
+	if block.Body == nil {
+		return ErrLightBlock
+	}
+
+	if a.Hash() != block.Header.AccountsHash {
+		return ErrAccountsHashMismatch
+	}
+
+	tx := a.Tree.Transaction()
+
+	err := revertBody(tx, block.Body, block.Header.Height)
+	if err != nil { return err }
+
+	a.commitTx(tx, b)
+	return nil
+}
+
+// Commits tx and adds the unsaved nodes to b, if not nil.
+// The caller writes b together with its chain updates.
+// If that fails, the accounts must be reloaded from storage.
+func (a *Accounts) commitTx(tx *Tree, b *storage.Batch) {
+	if b != nil {
+		tx.writeChanges(b)
+		a.Tree.markSaved()
+	}
+	tx.Commit()
+}
+
+// Hash of the accounts after applying body,
+// used to build the header of a new block
+func (a *Accounts) HashWithBody(body *core.BlockBody, height uint32, txCache core.TxCache) (core.Hash, error) {
+	tx := a.Tree.Transaction()
+	err := commitBody(tx, body, height, txCache)
+	if err != nil { return core.Hash{}, err }
+	return tx.RootHash(), nil
+}
+
+func getAccount(tree *Tree, address *core.Address) core.Account {
+	account := tree.Get(address)
+	if account == nil {
+		return core.InitialAccount()
+	}
+	return account
+}
+
+// Order: senders, recipients, contract commands,
+// miner reward, pruned accounts
+func commitBody(tree *Tree, body *core.BlockBody, height uint32, txCache core.TxCache) error {
+	for i, tx := range body.Txs {
+		e := tx.ToExtended()
+		sender, err := getAccount(tree, &e.Sender).WithOutgoingTx(tx, height, txCache)
+		if err != nil { return &core.BlockTxError{ Index: i, Err: err } }
+		tree.Put(&e.Sender, sender)
+	}
+
+	for i, tx := range body.Txs {
+		e := tx.ToExtended()
+		recipient, err := getAccount(tree, &e.Recipient).WithIncomingTx(tx, height)
+		if err != nil { return &core.BlockTxError{ Index: i, Err: err } }
+		tree.Put(&e.Recipient, recipient)
+	}
+
+	for i, tx := range body.Txs {
+		e := tx.ToExtended()
+		recipient, err := getAccount(tree, &e.Recipient).WithContractCommand(tx, height)
+		if err != nil { return &core.BlockTxError{ Index: i, Err: err } }
+		tree.Put(&e.Recipient, recipient)
+	}
+
+	coinbase, err := coinbaseTx(body, height)
+	if err != nil { return err }
+
+	miner, err := getAccount(tree, &body.MinerAddr).WithIncomingTx(coinbase, height)
+	if err != nil { return err }
+	tree.Put(&body.MinerAddr, miner)
+
+	return prune(tree, body)
+}
+
+// Reverse order of commitBody
+func revertBody(tree *Tree, body *core.BlockBody, height uint32) error {
+	for _, acc := range body.PrunedAccounts {
+		tree.Put(&acc.Address, acc.Account)
+	}
+
+	coinbase, err := coinbaseTx(body, height)
+	if err != nil { return err }
+
+	miner, err := getAccount(tree, &body.MinerAddr).RevertIncomingTx(coinbase, height)
+	if err != nil { return err }
+	tree.Put(&body.MinerAddr, miner)
+
+	for i := len(body.Txs) - 1; i >= 0; i-- {
+		tx := body.Txs[i]
+		e := tx.ToExtended()
+		recipient, err := getAccount(tree, &e.Recipient).RevertContractCommand(tx, height)
+		if err != nil { return &core.BlockTxError{ Index: i, Err: err } }
+		tree.Put(&e.Recipient, recipient)
+	}
+
+	for i := len(body.Txs) - 1; i >= 0; i-- {
+		tx := body.Txs[i]
+		e := tx.ToExtended()
+		recipient, err := getAccount(tree, &e.Recipient).RevertIncomingTx(tx, height)
+		if err != nil { return &core.BlockTxError{ Index: i, Err: err } }
+		tree.Put(&e.Recipient, recipient)
+	}
+
+	for i := len(body.Txs) - 1; i >= 0; i-- {
+		tx := body.Txs[i]
+		e := tx.ToExtended()
+		sender, err := getAccount(tree, &e.Sender).RevertOutgoingTx(tx, height)
+		if err != nil { return &core.BlockTxError{ Index: i, Err: err } }
+		tree.Put(&e.Sender, sender)
+	}
+
+	return nil
+}
+
+// Pseudo tx paying the block reward and fees to the miner
+func coinbaseTx(body *core.BlockBody, height uint32) (*core.ExtendedTx, error) {
+	reward := policy.BlockReward(height)
+	for _, tx := range body.Txs {
+		var err error
+		reward, err = policy.AddLuna(reward, uint64(tx.ToExtended().Fee))
+		if err != nil { return nil, err }
+	}
+
+	return &core.ExtendedTx{
+		Data: []byte{},
+		SenderType: core.BasicAccount,
+		Recipient: body.MinerAddr,
+		RecipientType: core.BasicAccount,
+		Value: core.Satoshi(reward),
+		ValidityStartHeight: height,
+	}, nil
+}
+
+// Removes the pruned accounts of the body, which
+// must be exactly the emptied contracts of the senders
+func prune(tree *Tree, body *core.BlockBody) error {
+	pruned := make(map[core.Address]bool)
+	for _, acc := range body.PrunedAccounts {
+		if !acc.IsToBePruned() ||
+			!core.AccountsEqual(getAccount(tree, &acc.Address), acc.Account) {
+			return ErrInvalidPrunedAccount
+		}
+		pruned[acc.Address] = true
+	}
+
+	for _, tx := range body.Txs {
+		sender := core.PrunedAccount{ Address: tx.ToExtended().Sender }
+		sender.Account = getAccount(tree, &sender.Address)
+		if sender.IsToBePruned() && !pruned[sender.Address] {
+			return ErrMissingPrunedAccount
+		}
+	}
+
+	for _, acc := range body.PrunedAccounts {
+		tree.Delete(&acc.Address)
+	}
+
+	return nil
+}
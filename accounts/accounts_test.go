@@ -0,0 +1,347 @@
+package accounts
+
+import (
+	"testing"
+	"os"
+	"errors"
+	"path/filepath"
+	"encoding/binary"
+	"github.com/terorie/go-nimiq/core"
+	"github.com/terorie/go-nimiq/ed25519"
+	"github.com/terorie/go-nimiq/policy"
+	"github.com/terorie/go-nimiq/storage"
+)
+
+var testPrivateKey = ed25519.PrivateKey{
+	0x33, 0x71, 0x4b, 0x23, 0x98, 0x3b, 0xea, 0x98,
+	0xd0, 0x5e, 0xd4, 0x75, 0x31, 0xb6, 0x5d, 0x7b,
+	0x91, 0xc0, 0xe9, 0x3a, 0x4b, 0xb2, 0x44, 0x46,
+	0x15, 0x31, 0x37, 0x7e, 0x1e, 0x39, 0xc9, 0xe8,
+	0x75, 0xa4, 0xb9, 0xa1, 0x7b, 0x68, 0x57, 0xca,
+	0x7d, 0x17, 0xee, 0x9b, 0xcd, 0x36, 0xb3, 0x6e,
+	0x6d, 0xf5, 0x22, 0x1e, 0x5f, 0x36, 0xfa, 0x69,
+	0x73, 0xd6, 0x4d, 0x57, 0x9c, 0xd2, 0x55, 0x51,
+}
+
+var testAddress = func() core.Address {
+	publicKey := core.PublicKey(ed25519.PublicKeyDerive(&testPrivateKey))
+	return publicKey.ToAddress()
+}()
+
+var testMinerAddr = core.Address{0xaa}
+
+// Signs an extended tx with the test key
+func signTx(tx *core.ExtendedTx) {
+	publicKey := ed25519.PublicKeyDerive(&testPrivateKey)
+	signature := ed25519.Sign(tx.SerializeContent(), &publicKey, &testPrivateKey)
+	pk, sig := core.PublicKey(publicKey), core.Signature(signature)
+	tx.Proof = core.NewSingleSigProof(&pk, &sig).Bytes()
+}
+
+// Tx creating a vesting contract owned by the test key
+func newVestingCreationTx(value core.Satoshi) *core.ExtendedTx {
+	data := make([]byte, 24)
+	copy(data, testAddress[:])
+	binary.BigEndian.PutUint32(data[20:], 1) // Step blocks
+
+	tx := &core.ExtendedTx{
+		Data: data,
+		Sender: testAddress,
+		SenderType: core.BasicAccount,
+		RecipientType: core.VestingAccount,
+		Value: value,
+		ValidityStartHeight: 1,
+		NetworkId: core.NetworkMain,
+		Flags: core.TxFlagContractCreation,
+	}
+	tx.Recipient = tx.ContractCreationAddress()
+	signTx(tx)
+	return tx
+}
+
+// Builds a block on top of accounts
+func newTestBlock(t *testing.T, a *Accounts, height uint32, body *core.BlockBody) *core.Block {
+	hash, err := a.HashWithBody(body, height, nil)
+	if err != nil { t.Fatal(err) }
+
+	return &core.Block{
+		Header: core.BlockHeader{
+			Version: core.BlockHeaderVersion,
+			AccountsHash: hash,
+			Height: height,
+		},
+		Body: body,
+	}
+}
+
+func newTestAccounts() *Accounts {
+	a := NewAccounts()
+	a.Tree.Put(&testAddress, &core.BasicWallet{ Balance: 100000 })
+	return a
+}
+
+func TestAccounts_Commit(t *testing.T) {
+	a := newTestAccounts()
+	initialHash := a.Hash()
+
+	payment := &core.BasicTx{
+		Recipient: core.Address{0x01},
+		Value: 500,
+		Fee: 10,
+		ValidityStartHeight: 1,
+		NetworkId: core.NetworkMain,
+	}
+	payment.Sign(&testPrivateKey)
+	creation := newVestingCreationTx(1000)
+
+	block := newTestBlock(t, a, 1, &core.BlockBody{
+		MinerAddr: testMinerAddr,
+		Txs: []core.Tx{ payment, creation },
+	})
+
+	if err := a.Commit(block, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if b := a.Get(&testAddress).GetBalance(); b != 100000 - 510 - 1000 {
+		t.Fatalf("Invalid sender balance: %d", b)
+	}
+	if b := a.Get(&payment.Recipient).GetBalance(); b != 500 {
+		t.Fatalf("Invalid recipient balance: %d", b)
+	}
+	contract, ok := a.Get(&creation.Recipient).(*core.VestingContract)
+	if !ok || contract.Balance != 1000 || contract.Owner != testAddress {
+		t.Fatal("Vesting contract not created")
+	}
+	if b := a.Get(&testMinerAddr).GetBalance(); uint64(b) != policy.BlockReward(1) + 10 {
+		t.Fatalf("Invalid miner reward: %d", b)
+	}
+
+	if err := a.Revert(block, nil); err != nil {
+		t.Fatal(err)
+	}
+	if a.Hash() != initialHash || a.Tree.Len() != 1 {
+		t.Fatal("Revert did not restore accounts")
+	}
+}
+
+func TestAccounts_Prune(t *testing.T) {
+	a := newTestAccounts()
+	creation := newVestingCreationTx(1000)
+	contractAddr := creation.Recipient
+
+	block1 := newTestBlock(t, a, 1, &core.BlockBody{
+		MinerAddr: testMinerAddr,
+		Txs: []core.Tx{ creation },
+	})
+	if err := a.Commit(block1, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	hash1 := a.Hash()
+
+	// Empty the contract
+	withdrawal := &core.ExtendedTx{
+		Data: []byte{},
+		Sender: contractAddr,
+		SenderType: core.VestingAccount,
+		Recipient: testAddress,
+		RecipientType: core.BasicAccount,
+		Value: 999,
+		Fee: 1,
+		ValidityStartHeight: 2,
+		NetworkId: core.NetworkMain,
+	}
+	signTx(withdrawal)
+
+	body := &core.BlockBody{
+		MinerAddr: testMinerAddr,
+		Txs: []core.Tx{ withdrawal },
+	}
+
+	// Emptied contract must be pruned
+	if _, err := a.HashWithBody(body, 2, nil); err != ErrMissingPrunedAccount {
+		t.Fatal("Missing pruned account accepted")
+	}
+
+	body.PrunedAccounts = []core.PrunedAccount{
+		{ Address: contractAddr, Account: &core.VestingContract{
+			Owner: testAddress,
+			VestingStepBlocks: 1,
+			VestingStepAmount: 1000,
+			VestingTotalAmount: 1000,
+		} },
+	}
+	block2 := newTestBlock(t, a, 2, body)
+
+	if err := a.Commit(block2, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if a.Tree.Get(&contractAddr) != nil {
+		t.Fatal("Contract not pruned")
+	}
+
+	if err := a.Revert(block2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if a.Hash() != hash1 || a.Get(&contractAddr).GetBalance() != 1000 {
+		t.Fatal("Pruned contract not restored")
+	}
+}
+
+func TestAccounts_CommitAtomic(t *testing.T) {
+	a := newTestAccounts()
+	hash := a.Hash()
+
+	valid := &core.BasicTx{
+		Recipient: core.Address{0x01},
+		Value: 500,
+		ValidityStartHeight: 1,
+		NetworkId: core.NetworkMain,
+	}
+	valid.Sign(&testPrivateKey)
+	overspend := &core.BasicTx{
+		Recipient: core.Address{0x02},
+		Value: 100000,
+		ValidityStartHeight: 1,
+		NetworkId: core.NetworkMain,
+	}
+	overspend.Sign(&testPrivateKey)
+
+	block := &core.Block{
+		Header: core.BlockHeader{ Height: 1 },
+		Body: &core.BlockBody{ Txs: []core.Tx{ valid, overspend } },
+	}
+
+	err := a.Commit(block, nil, nil)
+	var txErr *core.BlockTxError
+	if !errors.As(err, &txErr) || txErr.Index != 1 || txErr.Err != core.ErrAccount_InsufficientFunds {
+		t.Fatalf("Overspending tx accepted: %v", err)
+	}
+	if a.Hash() != hash {
+		t.Fatal("Failed commit changed accounts")
+	}
+
+	// Valid body, wrong accounts hash
+	block.Body.Txs = block.Body.Txs[:1]
+	if err := a.Commit(block, nil, nil); err != ErrAccountsHashMismatch {
+		t.Fatal("Wrong accounts hash accepted")
+	}
+	if a.Hash() != hash {
+		t.Fatal("Failed commit changed accounts")
+	}
+}
+
+func TestAccounts_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain.db")
+	db, err := storage.OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+
+	// Genesis state
+	a, err := LoadAccounts(db)
+	if err != nil { t.Fatal(err) }
+	a.Tree.Put(&testAddress, &core.BasicWallet{ Balance: 100000 })
+	for i, addr := range testAddresses(200) {
+		a.Tree.Put(&addr, &core.BasicWallet{ Balance: core.Satoshi(i + 1) })
+	}
+	if err := a.Tree.Save(db); err != nil {
+		t.Fatal(err)
+	}
+	initialHash := a.Hash()
+	info, _ := os.Stat(path)
+	genesisSize := info.Size()
+
+	payment := &core.BasicTx{
+		Recipient: core.Address{0x01},
+		Value: 500,
+		ValidityStartHeight: 1,
+		NetworkId: core.NetworkMain,
+	}
+	payment.Sign(&testPrivateKey)
+	block := newTestBlock(t, a, 1, &core.BlockBody{
+		MinerAddr: testMinerAddr,
+		Txs: []core.Tx{ payment },
+	})
+	b := new(storage.Batch)
+	if err := a.Commit(block, nil, b); err != nil {
+		t.Fatal(err)
+	}
+	core.PutBlock(b, block)
+	core.PutHead(b, block)
+	if err := db.Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the changed nodes are written
+	info, _ = os.Stat(path)
+	if growth := info.Size() - genesisSize; growth > genesisSize / 4 {
+		t.Fatalf("Commit wrote %d bytes", growth)
+	}
+
+	db.Close()
+	db, err = storage.OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+
+	a, err = LoadAccounts(db)
+	if err != nil { t.Fatal(err) }
+	if a.Hash() != block.Header.AccountsHash {
+		t.Fatal("Committed accounts not restored")
+	}
+	if head, err := core.GetHead(db); err != nil || head != block.Hash() {
+		t.Fatal("Chain head not stored")
+	}
+
+	b = new(storage.Batch)
+	if err := a.Revert(block, b); err != nil {
+		t.Fatal(err)
+	}
+	core.RevertHead(b, block)
+	if err := db.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	a, err = LoadAccounts(db)
+	if err != nil { t.Fatal(err) }
+	if a.Hash() != initialHash {
+		t.Fatal("Reverted accounts not stored")
+	}
+	if head, err := core.GetHead(db); err != nil || head != block.Header.PrevHash {
+		t.Fatal("Chain head not reverted")
+	}
+}
+
+func TestTree_Transaction(t *testing.T) {
+	addrs := testAddresses(10)
+	tree := NewTree()
+	for i := range addrs[:5] {
+		tree.Put(&addrs[i], &core.BasicWallet{ Balance: 1 })
+	}
+	hash := tree.RootHash()
+
+	tx := tree.Transaction()
+	tx.Delete(&addrs[0])
+	nested := tx.Transaction()
+	for i := range addrs[5:] {
+		nested.Put(&addrs[5 + i], &core.BasicWallet{ Balance: 2 })
+	}
+	nested.Commit()
+
+	if tree.RootHash() != hash || tree.Get(&addrs[6]) != nil {
+		t.Fatal("Transaction changed parent before commit")
+	}
+	if tx.Len() != 9 || tx.Get(&addrs[0]) != nil {
+		t.Fatalf("Invalid transaction state: %d accounts", tx.Len())
+	}
+
+	txHash := tx.RootHash()
+	tx.Commit()
+	if tree.RootHash() != txHash || tree.Len() != 9 {
+		t.Fatal("Commit not applied")
+	}
+
+	tx = tree.Transaction()
+	tx.Delete(&addrs[1])
+	tx.Abort()
+	if tx.RootHash() != txHash {
+		t.Fatal("Abort not applied")
+	}
+}
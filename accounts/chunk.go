@@ -0,0 +1,339 @@
+package accounts
+
+import (
+	"io"
+	"errors"
+	"strings"
+	"encoding/hex"
+	"encoding/binary"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+	"github.com/terorie/go-nimiq/core"
+)
+
+// Consecutive accounts of a tree for state sync:
+// The terminal nodes in address order, followed by
+// the last node (tail) with its proof.
+type Chunk struct {
+	Nodes []*Node
+	// Proof of the tail, Proof.Nodes[0] is the tail
+	Proof *Proof
+}
+
+// Max number of terminal nodes per chunk
+const ChunkSizeMax = 1000
+
+var (
+	ErrInvalidChunk = errors.New("invalid accounts tree chunk")
+	ErrUnmergeableChunk = errors.New("accounts tree chunk does not continue the tree")
+)
+
+// Builds a chunk of up to size accounts
+// with addresses after startPrefix ("" for the first chunk).
+// Returns nil if there are no more accounts after startPrefix.
+func (t *Tree) Chunk(startPrefix string, size int) *Chunk {
+	if size > ChunkSizeMax {
+		size = ChunkSizeMax
+	}
+
+	var nodes []*Node
+	t.collectTerminals(t.getNode(""), startPrefix, size, &nodes)
+
+	if len(nodes) == 0 {
+		// Empty tree: Only the root
+		if startPrefix == "" {
+			return &Chunk{ Proof: &Proof{ Nodes: []*Node{ t.getNode("") } } }
+		}
+		return nil
+	}
+
+	tail := nodes[len(nodes) - 1]
+	var address core.Address
+	hexToAddress(tail.Prefix, &address)
+
+	return &Chunk{
+		Nodes: nodes[:len(nodes) - 1],
+		Proof: t.Proof([]core.Address{ address }),
+	}
+}
+
+// Appends the terminal nodes below node after
+// startPrefix in order until there are size nodes
+func (t *Tree) collectTerminals(node *Node, startPrefix string, size int, nodes *[]*Node) {
+	if node.IsTerminal() {
+		if node.Prefix > startPrefix {
+			*nodes = append(*nodes, node)
+		}
+		return
+	}
+
+	for _, child := range node.Children {
+		if len(*nodes) >= size {
+			return
+		}
+		if child == nil { continue }
+
+		// Skip subtrees before startPrefix
+		childPrefix := node.Prefix + child.Suffix
+		if childPrefix < startPrefix && !strings.HasPrefix(startPrefix, childPrefix) {
+			continue
+		}
+
+		t.collectTerminals(t.getNode(childPrefix), startPrefix, size, nodes)
+	}
+}
+
+// Last node of the chunk, nil for the chunk of an empty tree
+func (c *Chunk) Tail() *Node {
+	if len(c.Proof.Nodes) == 0 || !c.Proof.Nodes[0].IsTerminal() {
+		return nil
+	}
+	return c.Proof.Nodes[0]
+}
+
+// All terminal nodes including the tail
+func (c *Chunk) TerminalNodes() []*Node {
+	if tail := c.Tail(); tail != nil {
+		return append(c.Nodes[:len(c.Nodes):len(c.Nodes)], tail)
+	}
+	return c.Nodes
+}
+
+// Checks the proof against accountsHash, that it is
+// exactly the path to the tail and that the nodes are ordered
+func (c *Chunk) Verify(accountsHash core.Hash) error {
+	if c.Proof == nil {
+		return ErrInvalidChunk
+	}
+
+	err := c.Proof.Verify(accountsHash)
+	if err != nil { return err }
+
+	if len(c.TerminalNodes()) > ChunkSizeMax {
+		return ErrInvalidChunk
+	}
+
+	tail := c.Tail()
+	if tail == nil {
+		// Only an empty tree has no tail
+		if len(c.Nodes) != 0 || len(c.Proof.Nodes) != 1 ||
+			c.Proof.root.childCount() != 0 {
+			return ErrInvalidChunk
+		}
+		return nil
+	}
+
+	// Branches from the tail up to the root
+	lastPrefix := tail.Prefix
+	for _, branch := range c.Proof.Nodes[1:] {
+		if branch.IsTerminal() || len(branch.Prefix) >= len(lastPrefix) ||
+			!strings.HasPrefix(tail.Prefix, branch.Prefix) {
+			return ErrInvalidChunk
+		}
+		lastPrefix = branch.Prefix
+	}
+	if lastPrefix != "" {
+		return ErrInvalidChunk
+	}
+
+	lastPrefix = ""
+	for _, node := range c.TerminalNodes() {
+		if !node.IsTerminal() || len(node.Prefix) != 2 * len(core.Address{}) ||
+			node.Prefix <= lastPrefix {
+			return ErrInvalidChunk
+		}
+		lastPrefix = node.Prefix
+	}
+
+	return nil
+}
+
+func (c *Chunk) SerializedSize() int {
+	size := 2 // Count
+	for _, node := range c.Nodes {
+		size += node.SerializedSize()
+	}
+	return size + c.Proof.SerializedSize()
+}
+
+// Wire format: count (uint16), terminal nodes, proof
+func (c *Chunk) Serialize(w io.Writer) error {
+	if len(c.Nodes) > 0xFFFF {
+		return ErrInvalidChunk
+	}
+
+	err := binary.Write(w, binary.BigEndian, uint16(len(c.Nodes)))
+	if err != nil { return err }
+
+	for _, node := range c.Nodes {
+		err = node.Serialize(w)
+		if err != nil { return err }
+	}
+
+	return c.Proof.Serialize(w)
+}
+
+func (c *Chunk) Deserialize(r io.Reader) error {
+	var count uint16
+	err := binary.Read(r, binary.BigEndian, &count)
+	if err != nil { return err }
+	// The tail follows in the proof
+	if int(count) >= ChunkSizeMax {
+		return ErrInvalidChunk
+	}
+
+	c.Nodes = make([]*Node, count)
+	for i := range c.Nodes {
+		c.Nodes[i] = new(Node)
+		err = c.Nodes[i].Deserialize(r)
+		if err != nil { return err }
+	}
+
+	c.Proof = new(Proof)
+	return c.Proof.Deserialize(r)
+}
+
+func (c *Chunk) Bytes() []byte {
+	return bu.Serialize(c.SerializedSize(), c.Serialize)
+}
+
+// Accounts tree rebuilt from chunks
+type PartialTree struct {
+	Tree *Tree
+	// Root hash of the complete tree
+	AccountsHash core.Hash
+	// Tail of the last chunk
+	lastPrefix string
+	complete bool
+}
+
+func NewPartialTree(accountsHash core.Hash) *PartialTree {
+	return &PartialTree{
+		Tree: NewTree(),
+		AccountsHash: accountsHash,
+	}
+}
+
+// Prefix to request the next chunk with
+func (p *PartialTree) LastPrefix() string {
+	return p.lastPrefix
+}
+
+func (p *PartialTree) IsComplete() bool {
+	return p.complete
+}
+
+// Adds the accounts of the next chunk.
+// The accounts received so far and the part of the tree
+// right of the tail from the proof must hash to AccountsHash.
+func (p *PartialTree) PushChunk(c *Chunk) error {
+	err := c.Verify(p.AccountsHash)
+	if err != nil { return err }
+
+	if p.complete {
+		return ErrUnmergeableChunk
+	}
+
+	tail := c.Tail()
+	if tail == nil {
+		// Chunk of an empty tree
+		p.complete = p.Tree.RootHash() == p.AccountsHash
+		return nil
+	}
+
+	// Must continue after the last chunk
+	if c.TerminalNodes()[0].Prefix <= p.lastPrefix {
+		return ErrUnmergeableChunk
+	}
+
+	tx := p.Tree.Transaction()
+	for _, node := range c.TerminalNodes() {
+		var address core.Address
+		hexToAddress(node.Prefix, &address)
+		tx.Put(&address, node.Account)
+	}
+
+	// The accounts up to the tail and the rest
+	// of the tree from the proof must add up
+	hash, ok := tx.rootHashWithProof(c.Proof)
+	if !ok || hash != p.AccountsHash {
+		return ErrUnmergeableChunk
+	}
+
+	tx.Commit()
+	p.lastPrefix = tail.Prefix
+	p.complete = p.Tree.RootHash() == p.AccountsHash
+	return nil
+}
+
+// Root hash of the tree made of the accounts of t up to the tail
+// of the proof and the subtrees right of the path to the tail
+// taken from the proof. Returns false if t has accounts that
+// don't fit the path.
+func (t *Tree) rootHashWithProof(proof *Proof) (core.Hash, bool) {
+	lower := proof.Nodes[0]
+	node := t.getNode(lower.Prefix)
+	if node == nil {
+		return core.Hash{}, false
+	}
+	hash := node.Hash()
+
+	for _, branch := range proof.Nodes[1:] {
+		full := *branch
+		pathIndex := branch.childIndex(lower.Prefix)
+		for i := range full.Children {
+			sub := t.subtree(branch.Prefix + hexDigits[i:i+1])
+			switch {
+			case i < pathIndex:
+				// Complete in t
+				full.Children[i] = nil
+				if sub != nil {
+					full.Children[i] = &ChildRef{
+						Suffix: sub.Prefix[len(branch.Prefix):],
+						Hash: sub.Hash(),
+					}
+				}
+			case i == pathIndex:
+				if sub == nil || !strings.HasPrefix(sub.Prefix, lower.Prefix) {
+					return core.Hash{}, false
+				}
+				full.Children[i] = &ChildRef{
+					Suffix: lower.Prefix[len(branch.Prefix):],
+					Hash: hash,
+				}
+			default:
+				// Right of the tail: Only in the proof
+				if sub != nil {
+					return core.Hash{}, false
+				}
+			}
+		}
+		hash = full.Hash()
+		lower = branch
+	}
+
+	return hash, true
+}
+
+const hexDigits = "0123456789abcdef"
+
+// Topmost node of t with a prefix starting with
+// prefix (holding all accounts below it), nil if none
+func (t *Tree) subtree(prefix string) *Node {
+	node := t.getNode("")
+	for !strings.HasPrefix(node.Prefix, prefix) {
+		if node.IsTerminal() {
+			return nil
+		}
+		childPrefix, ok := node.getChild(prefix)
+		if !ok || !(strings.HasPrefix(childPrefix, prefix) || strings.HasPrefix(prefix, childPrefix)) {
+			return nil
+		}
+		node = t.getNode(childPrefix)
+	}
+	return node
+}
+
+// Inverse of addressPrefix, prefix must be valid
+func hexToAddress(prefix string, address *core.Address) {
+	_, _ = hex.Decode(address[:], []byte(prefix))
+}
@@ -0,0 +1,187 @@
+package accounts
+
+import (
+	"testing"
+	"bytes"
+	"github.com/terorie/go-nimiq/core"
+)
+
+func newTestChunkTree() *Tree {
+	addrs := testAddresses(50)
+	tree := NewTree()
+	for i := range addrs {
+		tree.Put(&addrs[i], &core.BasicWallet{ Balance: core.Satoshi(i + 1) })
+	}
+	return tree
+}
+
+func TestPartialTree_PushChunk(t *testing.T) {
+	tree := newTestChunkTree()
+	partial := NewPartialTree(tree.RootHash())
+
+	chunks := 0
+	for !partial.IsComplete() {
+		chunk := tree.Chunk(partial.LastPrefix(), 7)
+
+		// Send over the wire
+		var chunk2 Chunk
+		if err := chunk2.Deserialize(bytes.NewReader(chunk.Bytes())); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := partial.PushChunk(&chunk2); err != nil {
+			t.Fatalf("Chunk %d rejected: %s", chunks, err)
+		}
+		chunks++
+		if chunks > 10 {
+			t.Fatal("Tree not complete after all chunks")
+		}
+	}
+
+	if chunks != 8 || partial.Tree.Len() != 50 {
+		t.Fatalf("Invalid chunk count %d or account count %d", chunks, partial.Tree.Len())
+	}
+	if partial.Tree.RootHash() != tree.RootHash() {
+		t.Fatal("Rebuilt tree differs")
+	}
+}
+
+func TestPartialTree_Invalid(t *testing.T) {
+	tree := newTestChunkTree()
+
+	partial := NewPartialTree(core.Hash{0x01})
+	if err := partial.PushChunk(tree.Chunk("", 7)); err != ErrProofRootMismatch {
+		t.Fatal("Chunk of other tree accepted")
+	}
+
+	// Skipped chunk
+	partial = NewPartialTree(tree.RootHash())
+	first := tree.Chunk("", 7)
+	second := tree.Chunk(first.Tail().Prefix, 7)
+	if err := partial.PushChunk(second); err != ErrUnmergeableChunk {
+		t.Fatal("Chunk with gap accepted")
+	}
+
+	// Same chunk twice
+	if err := partial.PushChunk(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := partial.PushChunk(first); err != ErrUnmergeableChunk {
+		t.Fatal("Repeated chunk accepted")
+	}
+
+	// Modified account
+	partial = NewPartialTree(tree.RootHash())
+	first.Nodes[2] = newTerminalNode(first.Nodes[2].Prefix, &core.BasicWallet{ Balance: 1000 })
+	if err := partial.PushChunk(first); err != ErrUnmergeableChunk {
+		t.Fatal("Modified chunk accepted")
+	}
+
+	// Unordered nodes
+	first = tree.Chunk("", 7)
+	first.Nodes[0], first.Nodes[1] = first.Nodes[1], first.Nodes[0]
+	if err := partial.PushChunk(first); err != ErrInvalidChunk {
+		t.Fatal("Unordered chunk accepted")
+	}
+
+	// Proof of more than the path to the tail
+	addrs := testAddresses(50)
+	hostile := &Chunk{ Proof: tree.Proof([]core.Address{ addrs[0], addrs[1] }) }
+	if err := NewPartialTree(tree.RootHash()).PushChunk(hostile); err != ErrInvalidChunk {
+		t.Fatal("Chunk with foreign proof nodes accepted")
+	}
+
+	// Root only chunk of a non-empty tree
+	rootOnly := &Chunk{ Proof: tree.Proof(nil) }
+	if err := NewPartialTree(tree.RootHash()).PushChunk(rootOnly); err != ErrInvalidChunk {
+		t.Fatal("Root only chunk of non-empty tree accepted")
+	}
+}
+
+func TestPartialTree_Forged(t *testing.T) {
+	var addrs [3]core.Address
+	addrs[0][0], addrs[0][1] = 0xab, 0x01
+	addrs[1][0], addrs[1][1] = 0xab, 0x02
+	addrs[2][0] = 0xc0
+	tree := NewTree()
+	for i := range addrs {
+		tree.Put(&addrs[i], &core.BasicWallet{ Balance: core.Satoshi(i + 1) })
+	}
+
+	// Made-up account in the subtree of the path to the tail
+	var fake core.Address
+	fake[0], fake[19] = 0xa0, 0x77
+	first := tree.Chunk("", 2)
+	forged := &Chunk{
+		Nodes: append([]*Node{ newTerminalNode(addressPrefix(&fake), &core.BasicWallet{ Balance: 5 }) }, first.Nodes...),
+		Proof: first.Proof,
+	}
+
+	partial := NewPartialTree(tree.RootHash())
+	if err := partial.PushChunk(forged); err != ErrUnmergeableChunk {
+		t.Fatal("Forged chunk accepted")
+	}
+
+	// Sync continues with the real chunks
+	for !partial.IsComplete() {
+		if err := partial.PushChunk(tree.Chunk(partial.LastPrefix(), 2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if partial.Tree.Len() != 3 {
+		t.Fatalf("Invalid account count: %d", partial.Tree.Len())
+	}
+}
+
+func TestChunk_SizeMax(t *testing.T) {
+	addrs := testAddresses(ChunkSizeMax + 1)
+	tree := NewTree()
+	for i := range addrs {
+		tree.Put(&addrs[i], &core.BasicWallet{ Balance: 1 })
+	}
+
+	// All accounts in one chunk
+	full := tree.Chunk("", ChunkSizeMax)
+	last := tree.Chunk(full.Tail().Prefix, 1)
+	oversized := &Chunk{ Nodes: full.TerminalNodes(), Proof: last.Proof }
+	if err := oversized.Verify(tree.RootHash()); err != ErrInvalidChunk {
+		t.Fatal("Oversized chunk accepted")
+	}
+
+	var chunk Chunk
+	if err := chunk.Deserialize(bytes.NewReader(oversized.Bytes())); err != ErrInvalidChunk {
+		t.Fatal("Oversized chunk decoded")
+	}
+}
+
+func TestTree_ChunkEnd(t *testing.T) {
+	tree := newTestChunkTree()
+
+	// Chunk up to the last account
+	chunk := tree.Chunk("", ChunkSizeMax)
+	if err := chunk.Verify(tree.RootHash()); err != nil {
+		t.Fatal(err)
+	}
+	if len(chunk.TerminalNodes()) != 50 {
+		t.Fatalf("Invalid account count: %d", len(chunk.TerminalNodes()))
+	}
+
+	// No accounts after the last one
+	if tree.Chunk(chunk.Tail().Prefix, 7) != nil {
+		t.Fatal("Chunk after last account")
+	}
+	if NewTree().Chunk("ab", 7) != nil {
+		t.Fatal("Chunk after start prefix in empty tree")
+	}
+}
+
+func TestPartialTree_Empty(t *testing.T) {
+	tree := NewTree()
+	partial := NewPartialTree(tree.RootHash())
+	if err := partial.PushChunk(tree.Chunk("", 7)); err != nil {
+		t.Fatal(err)
+	}
+	if !partial.IsComplete() {
+		t.Fatal("Empty tree not complete")
+	}
+}
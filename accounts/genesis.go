@@ -0,0 +1,37 @@
+package accounts
+
+import (
+	"io"
+	"bytes"
+	"encoding/binary"
+	"github.com/terorie/go-nimiq/core"
+)
+
+// Builds the genesis state of a chain from the serialized
+// genesis accounts. The root must match the accounts hash
+// of the genesis block.
+// Format: count (uint16), per account address and account
+func NewGenesisAccounts(g *core.GenesisConfig) (*Accounts, error) {
+	a := NewAccounts()
+	r := bytes.NewReader(g.Accounts)
+
+	var count uint16
+	err := binary.Read(r, binary.BigEndian, &count)
+	if err != nil { return nil, err }
+
+	for i := uint16(0); i < count; i++ {
+		var address core.Address
+		_, err = io.ReadFull(r, address[:])
+		if err != nil { return nil, err }
+
+		account, err := core.DeserializeAccount(r)
+		if err != nil { return nil, err }
+
+		a.Tree.Put(&address, account)
+	}
+
+	if a.Hash() != g.Header.AccountsHash {
+		return nil, ErrAccountsHashMismatch
+	}
+	return a, nil
+}
@@ -0,0 +1,62 @@
+package accounts
+
+import (
+	"os"
+	"bytes"
+	"testing"
+	"encoding/hex"
+	"encoding/binary"
+	"github.com/terorie/go-nimiq/core"
+)
+
+func TestNewGenesisAccounts(t *testing.T) {
+	addrs := testAddresses(10)
+	tree := NewTree()
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint16(len(addrs)))
+	for i := range addrs {
+		account := &core.VestingContract{ Balance: core.Satoshi(i + 1), Owner: addrs[0] }
+		tree.Put(&addrs[i], account)
+		buf.Write(addrs[i][:])
+		core.SerializeAccount(&buf, account)
+	}
+
+	g := &core.GenesisConfig{ Accounts: buf.Bytes() }
+	g.Header.AccountsHash = tree.RootHash()
+
+	a, err := NewGenesisAccounts(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Tree.Len() != len(addrs) {
+		t.Fatalf("Invalid account count: %d", a.Tree.Len())
+	}
+
+	g.Header.AccountsHash = core.Hash{0x01}
+	if _, err := NewGenesisAccounts(g); err != ErrAccountsHashMismatch {
+		t.Fatal("Genesis accounts of other chain accepted")
+	}
+}
+
+// accountsHash of the main network genesis block
+const mainGenesisAccountsHash = "1fefd44f1fa97185fda21e957545c97dc7643fa7e4efdd86e0aa4244d1e0bc5c"
+
+// The main network genesis accounts are not part of the tree,
+// place them at testdata/main-genesis-accounts.bin to run this.
+func TestNewGenesisAccounts_Main(t *testing.T) {
+	accounts, err := os.ReadFile("testdata/main-genesis-accounts.bin")
+	if os.IsNotExist(err) {
+		t.Skip("main network genesis accounts not available")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	g := &core.GenesisConfig{ NetworkId: core.NetworkMain, Accounts: accounts }
+	hash, _ := hex.DecodeString(mainGenesisAccountsHash)
+	copy(g.Header.AccountsHash[:], hash)
+
+	if _, err := NewGenesisAccounts(g); err != nil {
+		t.Fatal(err)
+	}
+}
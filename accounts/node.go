@@ -0,0 +1,239 @@
+package accounts
+
+import (
+	"io"
+	"bytes"
+	"errors"
+	"encoding/binary"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+	"github.com/terorie/go-nimiq/core"
+)
+
+// Node type enum
+type NodeType uint8
+const (
+	BranchNode   = NodeType(0x00)
+	TerminalNode = NodeType(0xff)
+)
+
+// Node of the accounts tree.
+// The prefix is the path from the root as lowercase
+// hex nibbles, terminal nodes have the full address.
+type Node struct {
+	Prefix string
+	// Set for terminal nodes
+	Account core.Account
+	// Set for branch nodes, indexed by
+	// the first nibble of the suffix
+	Children [16]*ChildRef
+}
+
+// Reference from a branch node to its child
+type ChildRef struct {
+	// Child prefix without the parent prefix
+	Suffix string
+	Hash core.Hash
+}
+
+var (
+	ErrInvalidNodeType = errors.New("invalid accounts tree node type")
+	ErrInvalidPrefix = errors.New("invalid accounts tree node prefix")
+)
+
+func newTerminalNode(prefix string, account core.Account) *Node {
+	return &Node{ Prefix: prefix, Account: account }
+}
+
+func newBranchNode(prefix string) *Node {
+	return &Node{ Prefix: prefix }
+}
+
+func (n *Node) Type() NodeType {
+	if n.Account != nil {
+		return TerminalNode
+	} else {
+		return BranchNode
+	}
+}
+
+func (n *Node) IsTerminal() bool {
+	return n.Account != nil
+}
+
+func (n *Node) SerializedSize() int {
+	size := 1 + // Type
+		1 + len(n.Prefix)
+	if n.IsTerminal() {
+		return size + n.Account.SerializedSize()
+	}
+	size += 1 // Child count
+	for _, child := range n.Children {
+		if child != nil {
+			size += 1 + len(child.Suffix) + 32
+		}
+	}
+	return size
+}
+
+// Wire format: type (uint8), prefix (uint8 length, ASCII)
+// Terminal: account
+// Branch: child count (uint8), per child suffix (uint8 length, ASCII), hash
+func (n *Node) Serialize(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.Grow(n.SerializedSize())
+
+	buf.WriteByte(uint8(n.Type()))
+	writeString(&buf, n.Prefix)
+
+	if n.IsTerminal() {
+		err := core.SerializeAccount(&buf, n.Account)
+		if err != nil { return err }
+	} else {
+		buf.WriteByte(uint8(n.childCount()))
+		for _, child := range n.Children {
+			if child == nil { continue }
+			writeString(&buf, child.Suffix)
+			buf.Write(child.Hash[:])
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (n *Node) Deserialize(r io.Reader) error {
+	var nodeType NodeType
+	err := binary.Read(r, binary.BigEndian, &nodeType)
+	if err != nil { return err }
+
+	n.Prefix, err = readString(r)
+	if err != nil { return err }
+	if !isNibbles(n.Prefix) {
+		return ErrInvalidPrefix
+	}
+
+	n.Account = nil
+	n.Children = [16]*ChildRef{}
+
+	switch nodeType {
+	case TerminalNode:
+		n.Account, err = core.DeserializeAccount(r)
+		return err
+
+	case BranchNode:
+		var childCount uint8
+		err = binary.Read(r, binary.BigEndian, &childCount)
+		if err != nil { return err }
+
+		for i := 0; i < int(childCount); i++ {
+			child := new(ChildRef)
+			child.Suffix, err = readString(r)
+			if err != nil { return err }
+			if child.Suffix == "" || !isNibbles(child.Suffix) {
+				return ErrInvalidPrefix
+			}
+
+			_, err = io.ReadFull(r, child.Hash[:])
+			if err != nil { return err }
+
+			n.Children[nibble(child.Suffix[0])] = child
+		}
+		return nil
+
+	default:
+		return ErrInvalidNodeType
+	}
+}
+
+func (n *Node) Bytes() []byte {
+	return bu.Serialize(n.SerializedSize(), n.Serialize)
+}
+
+func (n *Node) Hash() core.Hash {
+	return core.Blake2bHash(n.Bytes())
+}
+
+// Index of the child that prefix belongs to
+func (n *Node) childIndex(prefix string) int {
+	return nibble(prefix[len(n.Prefix)])
+}
+
+// Full prefix of the child that prefix belongs to, if any
+func (n *Node) getChild(prefix string) (string, bool) {
+	child := n.Children[n.childIndex(prefix)]
+	if child == nil {
+		return "", false
+	}
+	return n.Prefix + child.Suffix, true
+}
+
+// Copy of the node with the child at prefix set
+func (n *Node) withChild(prefix string, hash core.Hash) *Node {
+	c := *n
+	c.Children[n.childIndex(prefix)] = &ChildRef{
+		Suffix: prefix[len(n.Prefix):],
+		Hash: hash,
+	}
+	return &c
+}
+
+// Copy of the node without the child at prefix
+func (n *Node) withoutChild(prefix string) *Node {
+	c := *n
+	c.Children[n.childIndex(prefix)] = nil
+	return &c
+}
+
+func (n *Node) childCount() (count int) {
+	for _, child := range n.Children {
+		if child != nil {
+			count++
+		}
+	}
+	return
+}
+
+// Full prefix of the first child
+func (n *Node) firstChild() string {
+	for _, child := range n.Children {
+		if child != nil {
+			return n.Prefix + child.Suffix
+		}
+	}
+	return ""
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte(uint8(len(s)))
+	buf.WriteString(s)
+}
+
+func readString(r io.Reader) (string, error) {
+	var length [1]byte
+	_, err := io.ReadFull(r, length[:])
+	if err != nil { return "", err }
+
+	buf := make([]byte, length[0])
+	_, err = io.ReadFull(r, buf)
+	if err != nil { return "", err }
+
+	return string(buf), nil
+}
+
+// Value of a lowercase hex nibble
+func nibble(c byte) int {
+	if c >= 'a' {
+		return int(c - 'a') + 10
+	}
+	return int(c - '0')
+}
+
+func isNibbles(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,214 @@
+package accounts
+
+import (
+	"io"
+	"sort"
+	"errors"
+	"strings"
+	"encoding/binary"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+	"github.com/terorie/go-nimiq/core"
+)
+
+// Proof of the accounts at a set of addresses:
+// The tree nodes on the paths from the root
+// to the addresses in post-order (root last).
+// Absent accounts are proven by the nodes
+// showing that their path ends.
+type Proof struct {
+	Nodes []*Node
+	// Set by Verify
+	root *Node
+	index map[string]*Node
+}
+
+var (
+	ErrInvalidProof = errors.New("invalid accounts proof")
+	ErrProofRootMismatch = errors.New("accounts proof does not match accounts hash")
+	ErrNotInProof = errors.New("address not part of accounts proof")
+	ErrProofNotVerified = errors.New("accounts proof not verified")
+)
+
+// Builds the proof of the accounts at addresses
+func (t *Tree) Proof(addresses []core.Address) *Proof {
+	prefixes := make([]string, len(addresses))
+	for i := range addresses {
+		prefixes[i] = addressPrefix(&addresses[i])
+	}
+	sort.Strings(prefixes)
+
+	p := new(Proof)
+	t.collectProof(t.getNode(""), prefixes, p)
+	return p
+}
+
+// Adds the nodes proving prefixes below node to p,
+// returns whether node itself is part of the proof
+func (t *Tree) collectProof(node *Node, prefixes []string, p *Proof) bool {
+	// The root is always part of the proof,
+	// even if no addresses are proven
+	includeNode := node.IsTerminal() || node.Prefix == ""
+
+	for i := 0; i < len(prefixes); {
+		prefix := prefixes[i]
+
+		// Found the node or proved that the account doesn't exist
+		if !strings.HasPrefix(prefix, node.Prefix) || node.Prefix == prefix {
+			includeNode = true
+			i++
+			continue
+		}
+
+		childPrefix, ok := node.getChild(prefix)
+		if !ok {
+			// No child for prefix: Account doesn't exist
+			includeNode = true
+			i++
+			continue
+		}
+
+		// Prove all prefixes of the child at once
+		// (they are next to each other after sorting)
+		j := i + 1
+		for j < len(prefixes) && strings.HasPrefix(prefixes[j], childPrefix) {
+			j++
+		}
+
+		childNode := t.getNode(childPrefix)
+		if t.collectProof(childNode, prefixes[i:j], p) {
+			includeNode = true
+		}
+		i = j
+	}
+
+	if includeNode {
+		p.Nodes = append(p.Nodes, node)
+	}
+	return includeNode
+}
+
+func (p *Proof) SerializedSize() int {
+	size := 2 // Count
+	for _, node := range p.Nodes {
+		size += node.SerializedSize()
+	}
+	return size
+}
+
+// Wire format: count (uint16), nodes
+func (p *Proof) Serialize(w io.Writer) error {
+	if len(p.Nodes) > 0xFFFF {
+		return ErrInvalidProof
+	}
+
+	err := binary.Write(w, binary.BigEndian, uint16(len(p.Nodes)))
+	if err != nil { return err }
+
+	for _, node := range p.Nodes {
+		err = node.Serialize(w)
+		if err != nil { return err }
+	}
+
+	return nil
+}
+
+func (p *Proof) Deserialize(r io.Reader) error {
+	var count uint16
+	err := binary.Read(r, binary.BigEndian, &count)
+	if err != nil { return err }
+
+	p.Nodes = make([]*Node, count)
+	p.root, p.index = nil, nil
+	for i := range p.Nodes {
+		p.Nodes[i] = new(Node)
+		err = p.Nodes[i].Deserialize(r)
+		if err != nil { return err }
+	}
+
+	return nil
+}
+
+func (p *Proof) Bytes() []byte {
+	return bu.Serialize(p.SerializedSize(), p.Serialize)
+}
+
+// Checks that the nodes form a tree
+// with the root hash accountsHash
+func (p *Proof) Verify(accountsHash core.Hash) error {
+	p.root, p.index = nil, nil
+
+	// Nodes whose parent wasn't seen yet
+	var stack []*Node
+	index := make(map[string]*Node)
+
+	for _, node := range p.Nodes {
+		// Children precede their parent
+		for !node.IsTerminal() && len(stack) > 0 {
+			child := stack[len(stack) - 1]
+			if len(child.Prefix) <= len(node.Prefix) ||
+				!strings.HasPrefix(child.Prefix, node.Prefix) {
+				break
+			}
+
+			ref, ok := node.getChild(child.Prefix)
+			if !ok || ref != child.Prefix ||
+				node.Children[node.childIndex(child.Prefix)].Hash != child.Hash() {
+				return ErrInvalidProof
+			}
+			stack = stack[:len(stack) - 1]
+		}
+
+		if _, ok := index[node.Prefix]; ok {
+			return ErrInvalidProof
+		}
+		stack = append(stack, node)
+		index[node.Prefix] = node
+	}
+
+	// Only the root is left
+	if len(stack) != 1 || stack[0].Prefix != "" {
+		return ErrInvalidProof
+	}
+	if stack[0].Hash() != accountsHash {
+		return ErrProofRootMismatch
+	}
+
+	p.root, p.index = stack[0], index
+	return nil
+}
+
+// Returns the proven account at address
+// (InitialAccount if it doesn't exist).
+// The proof must be verified first.
+func (p *Proof) GetAccount(address *core.Address) (core.Account, error) {
+	if p.root == nil {
+		return nil, ErrProofNotVerified
+	}
+
+	prefix := addressPrefix(address)
+	node := p.root
+	for {
+		// Path diverges: Account doesn't exist
+		if !strings.HasPrefix(prefix, node.Prefix) {
+			return core.InitialAccount(), nil
+		}
+
+		if node.Prefix == prefix {
+			return node.Account, nil
+		}
+
+		if node.IsTerminal() {
+			return core.InitialAccount(), nil
+		}
+
+		childPrefix, ok := node.getChild(prefix)
+		if !ok {
+			return core.InitialAccount(), nil
+		}
+
+		node, ok = p.index[childPrefix]
+		if !ok {
+			return nil, ErrNotInProof
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package accounts
+
+import (
+	"testing"
+	"bytes"
+	"github.com/terorie/go-nimiq/core"
+)
+
+func newTestProofTree() (*Tree, []core.Address) {
+	addrs := testAddresses(40)
+	tree := NewTree()
+	for i := range addrs[:30] {
+		tree.Put(&addrs[i], &core.BasicWallet{ Balance: core.Satoshi(i + 1) })
+	}
+	return tree, addrs
+}
+
+func TestProof(t *testing.T) {
+	tree, addrs := newTestProofTree()
+
+	// Existing and absent accounts
+	proven := []core.Address{ addrs[0], addrs[1], addrs[2], addrs[17], addrs[35] }
+	proof := tree.Proof(proven)
+
+	if err := proof.Verify(tree.RootHash()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, i := range []int{ 0, 1, 2, 17 } {
+		acc, err := proof.GetAccount(&addrs[i])
+		if err != nil { t.Fatal(err) }
+		if acc.GetBalance() != core.Satoshi(i + 1) {
+			t.Fatalf("Invalid account %d", i)
+		}
+	}
+
+	acc, err := proof.GetAccount(&addrs[35])
+	if err != nil { t.Fatal(err) }
+	if !acc.IsInitial() {
+		t.Fatal("Absent account not initial")
+	}
+
+	// Addresses not covered by the proof
+	if _, err := proof.GetAccount(&addrs[20]); err != ErrNotInProof {
+		t.Fatal("Account outside of proof returned")
+	}
+}
+
+func TestProof_Serialize(t *testing.T) {
+	tree, addrs := newTestProofTree()
+	proof := tree.Proof(addrs[5:8])
+
+	var buf bytes.Buffer
+	if err := proof.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != proof.SerializedSize() {
+		t.Fatalf("Invalid serialized size: %d", buf.Len())
+	}
+
+	var proof2 Proof
+	if err := proof2.Deserialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proof2.GetAccount(&addrs[5]); err != ErrProofNotVerified {
+		t.Fatal("Unverified proof used")
+	}
+	if err := proof2.Verify(tree.RootHash()); err != nil {
+		t.Fatal(err)
+	}
+	if acc, err := proof2.GetAccount(&addrs[6]); err != nil || acc.GetBalance() != 7 {
+		t.Fatal("Invalid account after serialization")
+	}
+}
+
+func TestProof_Empty(t *testing.T) {
+	tree, _ := newTestProofTree()
+	for _, tree := range []*Tree{ tree, NewTree() } {
+		proof := tree.Proof(nil)
+		if len(proof.Nodes) != 1 {
+			t.Fatalf("Invalid node count of empty proof: %d", len(proof.Nodes))
+		}
+		if err := proof.Verify(tree.RootHash()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProof_Invalid(t *testing.T) {
+	tree, addrs := newTestProofTree()
+
+	proof := tree.Proof(addrs[:3])
+	if err := proof.Verify(core.Hash{0x01}); err != ErrProofRootMismatch {
+		t.Fatal("Proof of other tree accepted")
+	}
+
+	// Modified account
+	proof = tree.Proof(addrs[:3])
+	proof.Nodes[0] = newTerminalNode(proof.Nodes[0].Prefix, &core.BasicWallet{ Balance: 1000 })
+	if err := proof.Verify(tree.RootHash()); err != ErrInvalidProof {
+		t.Fatal("Modified proof accepted")
+	}
+
+	// Missing root
+	proof = tree.Proof(addrs[:3])
+	proof.Nodes = proof.Nodes[:len(proof.Nodes) - 1]
+	if err := proof.Verify(tree.RootHash()); err != ErrInvalidProof {
+		t.Fatal("Proof without root accepted")
+	}
+}
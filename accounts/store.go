@@ -0,0 +1,87 @@
+package accounts
+
+import (
+	"bytes"
+	"github.com/terorie/go-nimiq/storage"
+)
+
+// Key prefix of the tree nodes in storage,
+// followed by the node prefix
+var storageKeyPrefix = []byte("accounts/")
+
+// Writes the nodes changed since the last save to db.
+// db must hold the tree as of the last Save or LoadTree.
+// Uncommitted transactions are saved with their changes
+// and must be committed afterwards.
+func (t *Tree) Save(db storage.DB) error {
+	b := new(storage.Batch)
+	t.writeChanges(b)
+
+	err := db.Write(b)
+	if err != nil { return err }
+
+	t.markSaved()
+	return nil
+}
+
+// Adds the unsaved changes of the tree and
+// its transactions to the batch
+func (t *Tree) writeChanges(b *storage.Batch) {
+	seen := make(map[string]bool)
+	write := func(prefix string) {
+		if seen[prefix] { return }
+		seen[prefix] = true
+		if node := t.getNode(prefix); node != nil {
+			b.Put(storageKey(prefix), node.Bytes())
+		} else {
+			b.Delete(storageKey(prefix))
+		}
+	}
+
+	for tree := t; tree != nil; tree = tree.parent {
+		if tree.parent != nil {
+			for prefix := range tree.nodes {
+				write(prefix)
+			}
+		} else {
+			for prefix := range tree.dirty {
+				write(prefix)
+			}
+		}
+	}
+}
+
+// Clears the unsaved changes of the root tree
+func (t *Tree) markSaved() {
+	root := t
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.dirty = make(map[string]bool)
+}
+
+// Reads a tree written by Tree.Save
+func LoadTree(r storage.Reader) (*Tree, error) {
+	t := NewTree()
+
+	var err error
+	iterErr := r.Iterate(storageKeyPrefix, func(_, value []byte) bool {
+		node := new(Node)
+		err = node.Deserialize(bytes.NewReader(value))
+		if err != nil { return false }
+
+		t.putNode(node)
+		return true
+	})
+	if iterErr != nil { return nil, iterErr }
+	if err != nil { return nil, err }
+
+	t.markSaved()
+	return t, nil
+}
+
+func storageKey(prefix string) []byte {
+	key := make([]byte, 0, len(storageKeyPrefix) + len(prefix))
+	key = append(key, storageKeyPrefix...)
+	return append(key, prefix...)
+}
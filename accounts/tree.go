@@ -0,0 +1,254 @@
+// Package accounts implements the Nimiq accounts tree:
+// A Merkle radix trie over the hex addresses of all
+// non-initial accounts, and the state transitions of blocks.
+package accounts
+
+import (
+	"encoding/hex"
+	"github.com/terorie/go-nimiq/core"
+)
+
+// In-memory accounts tree.
+// The root is a branch node with an empty prefix.
+type Tree struct {
+	// Nodes by prefix
+	// (nil marks nodes deleted in a transaction)
+	nodes map[string]*Node
+	// Tree the transaction was started on
+	parent *Tree
+	// Prefixes of the nodes changed or removed
+	// since the last save (not set for transactions)
+	dirty map[string]bool
+}
+
+func NewTree() *Tree {
+	t := &Tree{
+		nodes: make(map[string]*Node),
+		dirty: make(map[string]bool),
+	}
+	t.putNode(newBranchNode(""))
+	return t
+}
+
+// Starts a transaction on the tree:
+// Changes to the returned tree only reach this tree on Commit.
+// Transactions can be nested.
+func (t *Tree) Transaction() *Tree {
+	return &Tree{
+		nodes: make(map[string]*Node),
+		parent: t,
+	}
+}
+
+// Applies the changes of the transaction to its parent.
+// The transaction can be used further afterwards.
+func (t *Tree) Commit() {
+	if t.parent == nil {
+		return
+	}
+	for prefix, node := range t.nodes {
+		if node != nil {
+			t.parent.putNode(node)
+		} else {
+			t.parent.removeNode(prefix)
+		}
+	}
+	t.nodes = make(map[string]*Node)
+}
+
+// Discards the changes of the transaction
+func (t *Tree) Abort() {
+	t.nodes = make(map[string]*Node)
+}
+
+func (t *Tree) getNode(prefix string) *Node {
+	for tree := t; tree != nil; tree = tree.parent {
+		if node, ok := tree.nodes[prefix]; ok {
+			return node
+		}
+	}
+	return nil
+}
+
+func (t *Tree) putNode(node *Node) {
+	t.nodes[node.Prefix] = node
+	if t.parent == nil {
+		t.dirty[node.Prefix] = true
+	}
+}
+
+func (t *Tree) removeNode(prefix string) {
+	if t.parent != nil {
+		t.nodes[prefix] = nil
+	} else {
+		delete(t.nodes, prefix)
+		t.dirty[prefix] = true
+	}
+}
+
+// Prefix of the terminal node of address
+func addressPrefix(address *core.Address) string {
+	return hex.EncodeToString(address[:])
+}
+
+// Returns the account at address or nil if there is none
+func (t *Tree) Get(address *core.Address) core.Account {
+	node := t.getNode(addressPrefix(address))
+	if node == nil || !node.IsTerminal() {
+		return nil
+	}
+	return node.Account
+}
+
+// Sets the account at address.
+// Initial accounts are removed from the tree.
+func (t *Tree) Put(address *core.Address, account core.Account) {
+	prefix := addressPrefix(address)
+
+	// Initial accounts are like non-existent ones
+	if account.IsInitial() {
+		if t.getNode(prefix) == nil {
+			return
+		}
+	}
+
+	t.insert(t.getNode(""), prefix, account, nil)
+}
+
+// Removes the account at address
+func (t *Tree) Delete(address *core.Address) {
+	t.Put(address, core.InitialAccount())
+}
+
+// Hash of the root node (accountsHash of block headers)
+func (t *Tree) RootHash() core.Hash {
+	return t.getNode("").Hash()
+}
+
+// Number of accounts in the tree
+func (t *Tree) Len() (count int) {
+	t.forEachNode(func(node *Node) {
+		if node.IsTerminal() {
+			count++
+		}
+	})
+	return
+}
+
+// Calls fn for every node, including the
+// changes of transactions
+func (t *Tree) forEachNode(fn func(node *Node)) {
+	// Topmost version of each node counts
+	seen := make(map[string]bool)
+	for tree := t; tree != nil; tree = tree.parent {
+		for prefix, node := range tree.nodes {
+			if seen[prefix] { continue }
+			seen[prefix] = true
+			if node != nil {
+				fn(node)
+			}
+		}
+	}
+}
+
+func (t *Tree) insert(node *Node, prefix string, account core.Account, rootPath []*Node) {
+	commonPrefix := commonPrefix(node.Prefix, prefix)
+
+	// The node prefix does not fully match the address:
+	// Split the node with a new parent
+	if len(commonPrefix) != len(node.Prefix) {
+		newChild := newTerminalNode(prefix, account)
+		t.putNode(newChild)
+
+		newParent := newBranchNode(commonPrefix).
+			withChild(node.Prefix, node.Hash()).
+			withChild(newChild.Prefix, newChild.Hash())
+		t.putNode(newParent)
+
+		t.updateKeys(newParent.Prefix, newParent.Hash(), rootPath)
+		return
+	}
+
+	// Found the node of the address: Update the account
+	if commonPrefix == prefix {
+		// Initial accounts get removed
+		if account.IsInitial() {
+			t.removeNode(node.Prefix)
+			t.prune(node.Prefix, rootPath)
+			return
+		}
+
+		node = newTerminalNode(node.Prefix, account)
+		t.putNode(node)
+
+		t.updateKeys(node.Prefix, node.Hash(), rootPath)
+		return
+	}
+
+	// Descend into the matching child if there is one
+	if childPrefix, ok := node.getChild(prefix); ok {
+		childNode := t.getNode(childPrefix)
+		t.insert(childNode, prefix, account, append(rootPath, node))
+		return
+	}
+
+	// Otherwise add the account as new child
+	newChild := newTerminalNode(prefix, account)
+	t.putNode(newChild)
+
+	node = node.withChild(newChild.Prefix, newChild.Hash())
+	t.putNode(node)
+
+	t.updateKeys(node.Prefix, node.Hash(), rootPath)
+}
+
+// Removes the node at prefix from its parents:
+// Empty branches are removed and branches with
+// a single child get merged into the child.
+func (t *Tree) prune(prefix string, rootPath []*Node) {
+	for i := len(rootPath) - 1; i >= 0; i-- {
+		node := rootPath[i].withoutChild(prefix)
+
+		// Merge branch with single child into the child
+		// (except the root, which always exists)
+		if node.childCount() == 1 && node.Prefix != "" {
+			t.removeNode(node.Prefix)
+
+			childNode := t.getNode(node.firstChild())
+			t.updateKeys(childNode.Prefix, childNode.Hash(), rootPath[:i])
+			return
+		}
+
+		// Branch still has children: Done
+		if node.childCount() > 0 || node.Prefix == "" {
+			t.putNode(node)
+			t.updateKeys(node.Prefix, node.Hash(), rootPath[:i])
+			return
+		}
+
+		// Branch is empty, continue with its parent
+		t.removeNode(node.Prefix)
+		prefix = node.Prefix
+	}
+}
+
+// Updates the hashes of the nodes on the root path
+// after the node at prefix changed to nodeHash
+func (t *Tree) updateKeys(prefix string, nodeHash core.Hash, rootPath []*Node) {
+	for i := len(rootPath) - 1; i >= 0; i-- {
+		node := rootPath[i].withChild(prefix, nodeHash)
+		t.putNode(node)
+
+		prefix = node.Prefix
+		nodeHash = node.Hash()
+	}
+}
+
+func commonPrefix(a, b string) string {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return a[:i]
+}
+
@@ -0,0 +1,215 @@
+package accounts
+
+import (
+	"testing"
+	"bytes"
+	"strings"
+	"math/rand"
+	"github.com/terorie/go-nimiq/core"
+	"github.com/terorie/go-nimiq/storage"
+)
+
+func testAddresses(n int) []core.Address {
+	rnd := rand.New(rand.NewSource(1))
+	addrs := make([]core.Address, n)
+	for i := range addrs {
+		rnd.Read(addrs[i][:])
+	}
+	// Addresses with long common prefixes
+	addrs[1] = addrs[0]
+	addrs[1][19] ^= 0x01
+	addrs[2] = addrs[0]
+	addrs[2][10] ^= 0x10
+	return addrs
+}
+
+func TestTree_Empty(t *testing.T) {
+	tree := NewTree()
+	// Branch node, empty prefix, no children
+	if tree.RootHash() != core.Blake2bHash([]byte{0x00, 0x00, 0x00}) {
+		t.Fatal("Invalid hash of empty tree")
+	}
+	if tree.Len() != 0 {
+		t.Fatal("Empty tree has accounts")
+	}
+}
+
+func TestTree_Put(t *testing.T) {
+	addrs := testAddresses(50)
+	tree := NewTree()
+	for i := range addrs {
+		tree.Put(&addrs[i], &core.BasicWallet{ Balance: core.Satoshi(i + 1) })
+	}
+
+	if tree.Len() != len(addrs) {
+		t.Fatalf("Invalid account count: %d", tree.Len())
+	}
+	for i := range addrs {
+		acc := tree.Get(&addrs[i])
+		if acc == nil || acc.GetBalance() != core.Satoshi(i + 1) {
+			t.Fatalf("Invalid account %d", i)
+		}
+	}
+
+	var unknown core.Address
+	if tree.Get(&unknown) != nil {
+		t.Fatal("Unknown account found")
+	}
+
+	// Root hash is independent of insertion order
+	tree2 := NewTree()
+	for _, i := range rand.New(rand.NewSource(2)).Perm(len(addrs)) {
+		tree2.Put(&addrs[i], &core.BasicWallet{ Balance: core.Satoshi(i + 1) })
+	}
+	if tree2.RootHash() != tree.RootHash() {
+		t.Fatal("Root hash depends on insertion order")
+	}
+
+	// Updates change the hash
+	hash := tree.RootHash()
+	tree.Put(&addrs[2], &core.BasicWallet{ Balance: 1000 })
+	if tree.RootHash() == hash {
+		t.Fatal("Root hash unchanged after update")
+	}
+}
+
+func TestTree_Delete(t *testing.T) {
+	addrs := testAddresses(30)
+
+	tree := NewTree()
+	for i := range addrs[:20] {
+		tree.Put(&addrs[i], &core.BasicWallet{ Balance: 1 })
+	}
+	hash := tree.RootHash()
+
+	// Adding and removing accounts restores the tree
+	for i := range addrs[20:] {
+		tree.Put(&addrs[20 + i], &core.BasicWallet{ Balance: 2 })
+	}
+	for i := range addrs[20:] {
+		tree.Delete(&addrs[20 + i])
+	}
+	if tree.RootHash() != hash {
+		t.Fatal("Root hash changed after delete")
+	}
+
+	// Initial account is a delete
+	tree.Put(&addrs[0], &core.BasicWallet{})
+	if tree.Get(&addrs[0]) != nil || tree.Len() != 19 {
+		t.Fatal("Initial account not removed")
+	}
+
+	for i := range addrs[1:20] {
+		tree.Delete(&addrs[1 + i])
+	}
+	if tree.RootHash() != NewTree().RootHash() || len(tree.nodes) != 1 {
+		t.Fatal("Tree not empty after deleting all accounts")
+	}
+}
+
+// Root hash of a small state, encoded by hand
+// in the node format of the reference client
+func TestTree_RootHash(t *testing.T) {
+	var addr1, addr2 core.Address
+	for i := range addr1 {
+		addr1[i], addr2[i] = 0x11, 0x11
+	}
+	addr2[0] = 0x12
+
+	tree := NewTree()
+	tree.Put(&addr1, &core.BasicWallet{ Balance: 0x0102 })
+	tree.Put(&addr2, &core.BasicWallet{ Balance: 0x0304 })
+
+	prefix1 := strings.Repeat("1", 40)
+	prefix2 := "12" + strings.Repeat("1", 38)
+	terminal := func(prefix string, balance []byte) core.Hash {
+		raw := append([]byte{0xff, 40}, prefix...)
+		// Basic account type, balance
+		raw = append(raw, 0x00)
+		raw = append(raw, balance...)
+		return core.Blake2bHash(raw)
+	}
+	hash1 := terminal(prefix1, []byte{0, 0, 0, 0, 0, 0, 0x01, 0x02})
+	hash2 := terminal(prefix2, []byte{0, 0, 0, 0, 0, 0, 0x03, 0x04})
+
+	// Branch "1" with children "1..." and "2..."
+	branch := []byte{0x00, 1, '1', 2}
+	branch = append(branch, 39)
+	branch = append(branch, prefix1[1:]...)
+	branch = append(branch, hash1[:]...)
+	branch = append(branch, 39)
+	branch = append(branch, prefix2[1:]...)
+	branch = append(branch, hash2[:]...)
+	branchHash := core.Blake2bHash(branch)
+
+	root := []byte{0x00, 0, 1, 1, '1'}
+	root = append(root, branchHash[:]...)
+
+	if tree.RootHash() != core.Blake2bHash(root) {
+		t.Fatal("Root hash doesn't match reference encoding")
+	}
+}
+
+func TestNode_Serialize(t *testing.T) {
+	addrs := testAddresses(3)
+	tree := NewTree()
+	for i := range addrs {
+		tree.Put(&addrs[i], &core.VestingContract{ Balance: 5 })
+	}
+
+	for prefix, node := range tree.nodes {
+		var buf bytes.Buffer
+		if err := node.Serialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() != node.SerializedSize() {
+			t.Fatalf("Invalid serialized size of %s", prefix)
+		}
+
+		var node2 Node
+		if err := node2.Deserialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if node2.Hash() != node.Hash() || node2.Prefix != prefix {
+			t.Fatalf("Node %s changed after serialization", prefix)
+		}
+	}
+}
+
+func TestTree_Save(t *testing.T) {
+	addrs := testAddresses(20)
+	tree := NewTree()
+	for i := range addrs {
+		tree.Put(&addrs[i], &core.BasicWallet{ Balance: core.Satoshi(i + 1) })
+	}
+
+	db := storage.NewMemoryDB()
+	if err := tree.Save(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving again only writes the changed path to the root
+	tree.Delete(&addrs[0])
+	b := new(storage.Batch)
+	tree.writeChanges(b)
+	if b.Len() == 0 || b.Len() > 2 * len(addrs[0]) {
+		t.Fatalf("Invalid change count: %d", b.Len())
+	}
+	if err := tree.Save(db); err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	tree.writeChanges(b)
+	if b.Len() != 0 {
+		t.Fatal("Changes left after save")
+	}
+
+	tree2, err := LoadTree(db)
+	if err != nil { t.Fatal(err) }
+	if tree2.RootHash() != tree.RootHash() || tree2.Len() != 19 {
+		t.Fatal("Loaded tree differs")
+	}
+	if acc := tree2.Get(&addrs[5]); acc == nil || acc.GetBalance() != 6 {
+		t.Fatal("Invalid loaded account")
+	}
+}
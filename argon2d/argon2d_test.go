@@ -0,0 +1,30 @@
+package argon2d
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Test vector from RFC 9106, section 5.1
+func TestDeriveKey(t *testing.T) {
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+
+	tag := []byte{
+		0x51, 0x2b, 0x39, 0x1b, 0x6f, 0x11, 0x62, 0x97,
+		0x53, 0x71, 0xd3, 0x09, 0x19, 0x73, 0x42, 0x94,
+		0xf8, 0x68, 0xe3, 0xbe, 0x39, 0x84, 0xf3, 0xc1,
+		0xa1, 0x3a, 0x4d, 0xb9, 0xfa, 0xbe, 0x4a, 0xcb,
+	}
+
+	key := deriveKey(password, salt, secret, data, 3, 32, 4, 32)
+	if !bytes.Equal(key, tag) {
+		t.Fatalf("Invalid Argon2d tag.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			tag, key,
+		)
+	}
+}
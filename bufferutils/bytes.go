@@ -1,7 +1,19 @@
 package bufferutils
 
-import "encoding/binary"
+import (
+	"io"
+	"bytes"
+	"encoding/binary"
+)
 
 // Nimiq uses network-order
 var bin = binary.BigEndian
 
+// Serializes into a new buffer, sizeHint is the expected size.
+// Writes to bytes.Buffer never fail, so the error is dropped.
+func Serialize(sizeHint int, serialize func(w io.Writer) error) []byte {
+	var buf bytes.Buffer
+	buf.Grow(sizeHint)
+	_ = serialize(&buf)
+	return buf.Bytes()
+}
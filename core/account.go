@@ -1,13 +1,157 @@
 package core
 
-type Account struct {
-	Type AccountType
+import (
+	"io"
+	"bytes"
+	"errors"
+	"encoding/binary"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+)
+
+// State of an account in the accounts tree.
+// Implemented by *BasicWallet, *VestingContract and *HtlcContract.
+type Account interface {
+	Type() AccountType
+	GetBalance() Satoshi
+	// Size of the account as written by SerializeAccount
+	// (including the account type byte)
+	SerializedSize() int
+	// Initial accounts are treated as non-existent:
+	// Basic accounts without balance, contracts never are
+	IsInitial() bool
+
+	// State transitions, see accounttx.go
+	WithOutgoingTx(tx Tx, height uint32, txCache TxCache) (Account, error)
+	RevertOutgoingTx(tx Tx, height uint32) (Account, error)
+	WithIncomingTx(tx Tx, height uint32) (Account, error)
+	RevertIncomingTx(tx Tx, height uint32) (Account, error)
+	WithContractCommand(tx Tx, height uint32) (Account, error)
+	RevertContractCommand(tx Tx, height uint32) (Account, error)
+}
+
+// Account of a key pair or multisig wallet
+type BasicWallet struct {
 	Balance Satoshi
 }
 
-const AccountSize =
-	1 + // AccountType uint8
-	8   // Balance uint64
+// Serialized size without the account type
+const BasicWalletSize =
+	8 // Balance
+
+// Returns the state of every address
+// without an account in the tree
+func InitialAccount() Account {
+	return &BasicWallet{}
+}
+
+var ErrUnsupportedAccountType = errors.New("unsupported account type")
+
+func DeserializeAccount(r io.Reader) (Account, error) {
+	// Read account type (1 byte)
+	var accountType AccountType
+	err := binary.Read(r, binary.BigEndian, &accountType)
+	if err != nil { return nil, err }
+
+	switch accountType {
+	case BasicAccount:
+		var buf [BasicWalletSize]byte
+		_, err = io.ReadFull(r, buf[:])
+		if err != nil { return nil, err }
+
+		basic := new(BasicWallet)
+		basic.Deserialize(&buf)
+		return basic, nil
+
+	case VestingAccount:
+		var buf [VestingContractSize]byte
+		_, err = io.ReadFull(r, buf[:])
+		if err != nil { return nil, err }
+
+		vesting := new(VestingContract)
+		vesting.Deserialize(&buf)
+		return vesting, nil
+
+	case HtlcAccount:
+		var buf [HtlcContractSize]byte
+		_, err = io.ReadFull(r, buf[:])
+		if err != nil { return nil, err }
+
+		htlc := new(HtlcContract)
+		htlc.Deserialize(&buf)
+		return htlc, nil
+
+	default:
+		return nil, ErrUnsupportedAccountType
+	}
+}
+
+// Writes the account type byte followed by the account.
+// The output can be read back with DeserializeAccount.
+func SerializeAccount(w io.Writer, a Account) error {
+	// Write account type (1 byte)
+	_, err := w.Write([]byte{uint8(a.Type())})
+	if err != nil { return err }
+
+	switch t := a.(type) {
+	case *BasicWallet:
+		var buf [BasicWalletSize]byte
+		t.Serialize(&buf)
+		_, err = w.Write(buf[:])
+		return err
+
+	case *VestingContract:
+		var buf [VestingContractSize]byte
+		t.Serialize(&buf)
+		_, err = w.Write(buf[:])
+		return err
+
+	case *HtlcContract:
+		var buf [HtlcContractSize]byte
+		t.Serialize(&buf)
+		_, err = w.Write(buf[:])
+		return err
+
+	default:
+		return ErrUnsupportedAccountType
+	}
+}
+
+func AccountBytes(a Account) []byte {
+	return bu.Serialize(a.SerializedSize(), func(w io.Writer) error {
+		return SerializeAccount(w, a)
+	})
+}
+
+// Accounts are equal if their serializations are equal
+func AccountsEqual(a, b Account) bool {
+	return bytes.Equal(AccountBytes(a), AccountBytes(b))
+}
+
+func (_ *BasicWallet) Type() AccountType {
+	return BasicAccount
+}
+
+func (b *BasicWallet) GetBalance() Satoshi {
+	return b.Balance
+}
+
+func (_ *BasicWallet) SerializedSize() int {
+	return 1 + BasicWalletSize
+}
+
+func (b *BasicWallet) IsInitial() bool {
+	return b.Balance == 0
+}
+
+func (b *BasicWallet) Deserialize(buf *[BasicWalletSize]byte) {
+	sl := bu.ReadBytes(buf[:])
+	b.Balance = Satoshi(sl.Uint64())
+}
+
+func (b *BasicWallet) Serialize(buf *[BasicWalletSize]byte) {
+	sl := bu.WriteBytes(buf[:])
+	sl.Uint64(uint64(b.Balance))
+}
 
 // Account type enum
 type AccountType uint8
@@ -25,3 +169,47 @@ func (a AccountType) String() string {
 	default: return "Invalid account type"
 	}
 }
+
+// Error codes
+type AccountError uint8
+
+const (
+	_ = AccountError(iota)
+	ErrAccount_InsufficientFunds
+	ErrAccount_FundsLocked
+	ErrAccount_InvalidProof
+	ErrAccount_HtlcExpired
+	ErrAccount_HtlcNotExpired
+	ErrAccount_BalanceOverflow
+	ErrAccount_TxNotValid
+	ErrAccount_DoubleTx
+	ErrAccount_TypeMismatch
+	ErrAccount_IllegalIncomingTx
+)
+
+func (a AccountError) Error() string {
+	switch a {
+	case ErrAccount_InsufficientFunds:
+		return "account error: insufficient funds"
+	case ErrAccount_FundsLocked:
+		return "account error: funds are locked"
+	case ErrAccount_InvalidProof:
+		return "account error: proof does not match account"
+	case ErrAccount_HtlcExpired:
+		return "account error: htlc timed out"
+	case ErrAccount_HtlcNotExpired:
+		return "account error: htlc not timed out yet"
+	case ErrAccount_BalanceOverflow:
+		return "account error: balance overflows"
+	case ErrAccount_TxNotValid:
+		return "account error: tx not valid at this block height"
+	case ErrAccount_DoubleTx:
+		return "account error: tx already included"
+	case ErrAccount_TypeMismatch:
+		return "account error: tx account type does not match account"
+	case ErrAccount_IllegalIncomingTx:
+		return "account error: contract can't receive tx"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+)
+
+func TestDeserializeAccount(t *testing.T) {
+	accounts := []Account{
+		&BasicWallet{ Balance: 1000 },
+		&VestingContract{
+			Balance: 1000,
+			Owner: Address{0x01},
+			VestingStart: 1,
+			VestingStepBlocks: 2,
+			VestingStepAmount: 3,
+			VestingTotalAmount: 4,
+		},
+		&HtlcContract{
+			Balance: 1000,
+			Sender: Address{0x01},
+			Recipient: Address{0x02},
+			HashAlgorithm: HashAlgorithmBlake2b,
+			HashRoot: Hash{0x03},
+			HashCount: 1,
+			Timeout: 2,
+			TotalAmount: 3,
+		},
+	}
+
+	for _, account := range accounts {
+		buf := AccountBytes(account)
+
+		if len(buf) != account.SerializedSize() {
+			t.Fatalf("Invalid serialized size of %s: %d", account.Type(), len(buf))
+		}
+
+		if buf[0] != uint8(account.Type()) {
+			t.Fatalf("Invalid type byte of %s: %d", account.Type(), buf[0])
+		}
+
+		decoded, err := DeserializeAccount(bytes.NewReader(buf))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if decoded.Type() != account.Type() || decoded.GetBalance() != 1000 {
+			t.Fatalf("Failed deserializing %s.", account.Type())
+		}
+
+		if !AccountsEqual(decoded, account) {
+			t.Fatalf("Deserialized %s not equal to original.", account.Type())
+		}
+	}
+
+	if AccountsEqual(accounts[0], &BasicWallet{ Balance: 999 }) {
+		t.Fatal("Accounts with different balance equal.")
+	}
+
+	_, err := DeserializeAccount(bytes.NewReader([]byte{0x03}))
+	if err != ErrUnsupportedAccountType {
+		t.Fatal("Invalid account type accepted.")
+	}
+}
+
+func TestAccount_IsInitial(t *testing.T) {
+	if !InitialAccount().IsInitial() || !(&BasicWallet{}).IsInitial() {
+		t.Fatal("Empty basic account not initial.")
+	}
+
+	if (&BasicWallet{ Balance: 1 }).IsInitial() {
+		t.Fatal("Basic account with balance initial.")
+	}
+
+	if (&VestingContract{}).IsInitial() || (&HtlcContract{}).IsInitial() {
+		t.Fatal("Contract initial.")
+	}
+
+	if !AccountsEqual(InitialAccount(), &BasicWallet{}) {
+		t.Fatal("Empty basic account not equal to initial account.")
+	}
+
+	initial := InitialAccount().(*BasicWallet)
+	initial.Balance = 1
+	if !InitialAccount().IsInitial() {
+		t.Fatal("Initial account shared between callers.")
+	}
+}
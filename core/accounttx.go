@@ -0,0 +1,178 @@
+package core
+
+// Account state transitions:
+// Applying a block runs for every tx (in this order)
+// WithOutgoingTx on the sender, WithIncomingTx and
+// WithContractCommand on the recipient.
+// Reverting runs the Revert* counterparts in reverse.
+// Transitions never modify the receiver.
+
+import "github.com/terorie/go-nimiq/policy"
+
+// Lookup of txs that are already included
+// in the blocks of the validity window
+type TxCache interface {
+	ContainsTx(hash Hash) bool
+}
+
+// Checks the validity window and double spends
+func verifyTxValidity(tx *ExtendedTx, height uint32, txCache TxCache) error {
+	if height < tx.ValidityStartHeight ||
+		uint64(height) >= uint64(tx.ValidityStartHeight) + policy.TransactionValidityWindow {
+		return ErrAccount_TxNotValid
+	}
+
+	if txCache != nil && txCache.ContainsTx(tx.Hash()) {
+		return ErrAccount_DoubleTx
+	}
+
+	return nil
+}
+
+func balanceAdd(balance, value Satoshi) (Satoshi, error) {
+	sum := balance + value
+	if sum < balance {
+		return 0, ErrAccount_BalanceOverflow
+	}
+	return sum, nil
+}
+
+func balanceSub(balance, value Satoshi) (Satoshi, error) {
+	if value > balance {
+		return 0, ErrAccount_InsufficientFunds
+	}
+	return balance - value, nil
+}
+
+// Value plus fee
+func txAmount(tx *ExtendedTx) (Satoshi, error) {
+	return balanceAdd(tx.Value, tx.Fee)
+}
+
+// Balance after sending tx
+func outgoingBalance(balance Satoshi, tx *ExtendedTx, height uint32, txCache TxCache) (Satoshi, error) {
+	err := verifyTxValidity(tx, height, txCache)
+	if err != nil { return 0, err }
+
+	amount, err := txAmount(tx)
+	if err != nil { return 0, err }
+
+	return balanceSub(balance, amount)
+}
+
+// Balance before sending tx
+func revertOutgoingBalance(balance Satoshi, tx *ExtendedTx) (Satoshi, error) {
+	amount, err := txAmount(tx)
+	if err != nil { return 0, err }
+
+	return balanceAdd(balance, amount)
+}
+
+func (b *BasicWallet) WithOutgoingTx(tx Tx, height uint32, txCache TxCache) (Account, error) {
+	e := tx.ToExtended()
+	if e.SenderType != BasicAccount {
+		return nil, ErrAccount_TypeMismatch
+	}
+
+	balance, err := outgoingBalance(b.Balance, e, height, txCache)
+	if err != nil { return nil, err }
+
+	return &BasicWallet{ Balance: balance }, nil
+}
+
+func (b *BasicWallet) RevertOutgoingTx(tx Tx, height uint32) (Account, error) {
+	balance, err := revertOutgoingBalance(b.Balance, tx.ToExtended())
+	if err != nil { return nil, err }
+
+	return &BasicWallet{ Balance: balance }, nil
+}
+
+func (b *BasicWallet) WithIncomingTx(tx Tx, height uint32) (Account, error) {
+	e := tx.ToExtended()
+
+	// Contract creations go to basic accounts first
+	if e.RecipientType != BasicAccount && !e.HasFlag(TxFlagContractCreation) {
+		return nil, ErrAccount_TypeMismatch
+	}
+
+	balance, err := balanceAdd(b.Balance, e.Value)
+	if err != nil { return nil, err }
+
+	return &BasicWallet{ Balance: balance }, nil
+}
+
+func (b *BasicWallet) RevertIncomingTx(tx Tx, height uint32) (Account, error) {
+	balance, err := balanceSub(b.Balance, tx.ToExtended().Value)
+	if err != nil { return nil, err }
+
+	return &BasicWallet{ Balance: balance }, nil
+}
+
+// Turns the account into the contract created by tx
+// (keeping the balance). Other txs don't change it.
+func (b *BasicWallet) WithContractCommand(tx Tx, height uint32) (Account, error) {
+	e := tx.ToExtended()
+	if !e.HasFlag(TxFlagContractCreation) {
+		return b, nil
+	}
+
+	switch e.RecipientType {
+	case VestingAccount:
+		d, err := e.VestingCreationData()
+		if err != nil { return nil, err }
+
+		return &VestingContract{
+			Balance: b.Balance,
+			Owner: d.Owner,
+			VestingStart: d.VestingStart,
+			VestingStepBlocks: d.VestingStepBlocks,
+			VestingStepAmount: d.VestingStepAmount,
+			VestingTotalAmount: d.VestingTotalAmount,
+		}, nil
+
+	case HtlcAccount:
+		d, err := e.HtlcCreationData()
+		if err != nil { return nil, err }
+
+		return &HtlcContract{
+			Balance: b.Balance,
+			Sender: d.Sender,
+			Recipient: d.Recipient,
+			HashAlgorithm: d.HashAlgorithm,
+			HashRoot: d.HashRoot,
+			HashCount: d.HashCount,
+			Timeout: d.Timeout,
+			TotalAmount: e.Value,
+		}, nil
+
+	default:
+		return nil, ErrTx_InvalidContractCreation
+	}
+}
+
+func (b *BasicWallet) RevertContractCommand(tx Tx, height uint32) (Account, error) {
+	return b, nil
+}
+
+// Contracts can only receive funds on creation,
+// when they are still basic accounts
+func contractIncomingTx() (Account, error) {
+	return nil, ErrAccount_IllegalIncomingTx
+}
+
+// Contracts already exist when a tx creates them again
+func contractWithContractCommand(a Account, tx Tx) (Account, error) {
+	if tx.ToExtended().HasFlag(TxFlagContractCreation) {
+		return nil, ErrAccount_IllegalIncomingTx
+	}
+	return a, nil
+}
+
+// Reverting the creation turns
+// the contract back into a basic account
+func contractRevertContractCommand(a Account, tx Tx) (Account, error) {
+	if tx.ToExtended().HasFlag(TxFlagContractCreation) {
+		return &BasicWallet{ Balance: a.GetBalance() }, nil
+	}
+	return a, nil
+}
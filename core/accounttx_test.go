@@ -0,0 +1,157 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+	"github.com/terorie/go-nimiq/policy"
+)
+
+type testTxCache map[Hash]bool
+
+func (c testTxCache) ContainsTx(hash Hash) bool {
+	return c[hash]
+}
+
+func TestBasicWallet_WithOutgoingTx(t *testing.T) {
+	tx := &BasicTx{
+		Recipient: Address{0x01},
+		Value: 100,
+		Fee: 1,
+		ValidityStartHeight: 1000,
+		NetworkId: 42,
+	}
+	tx.Sign(&testPrivateKey)
+
+	sender := &BasicWallet{ Balance: 1000 }
+
+	account, err := sender.WithOutgoingTx(tx, 1000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.GetBalance() != 899 {
+		t.Fatalf("Invalid balance after sending: %d", account.GetBalance())
+	}
+	if sender.Balance != 1000 {
+		t.Fatal("Transition modified original account.")
+	}
+
+	reverted, err := account.RevertOutgoingTx(tx, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !AccountsEqual(reverted, sender) {
+		t.Fatal("Revert did not restore sender.")
+	}
+
+	if _, err = sender.WithOutgoingTx(tx, 999, nil); err != ErrAccount_TxNotValid {
+		t.Fatal("Tx before validity start accepted.")
+	}
+
+	if _, err = sender.WithOutgoingTx(tx, 1000 + policy.TransactionValidityWindow, nil); err != ErrAccount_TxNotValid {
+		t.Fatal("Tx after validity window accepted.")
+	}
+
+	cache := testTxCache{ tx.Hash(): true }
+	if _, err = sender.WithOutgoingTx(tx, 1000, cache); err != ErrAccount_DoubleTx {
+		t.Fatal("Double tx accepted.")
+	}
+
+	poor := &BasicWallet{ Balance: 100 }
+	if _, err = poor.WithOutgoingTx(tx, 1000, nil); err != ErrAccount_InsufficientFunds {
+		t.Fatal("Tx exceeding balance accepted.")
+	}
+
+	// Recipient side
+	recipient, err := InitialAccount().WithIncomingTx(tx, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recipient.GetBalance() != 100 {
+		t.Fatalf("Invalid balance after receiving: %d", recipient.GetBalance())
+	}
+
+	recipient, err = recipient.RevertIncomingTx(tx, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recipient.IsInitial() {
+		t.Fatal("Revert did not restore recipient.")
+	}
+}
+
+func TestAccount_ContractLifecycle(t *testing.T) {
+	owner := testPublicKey.ToAddress()
+
+	// Vesting contract unlocking 100 every 10 blocks
+	creationTx := newTestVestingCreationTx(append(owner[:],
+		0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x0a,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64))
+
+	// Apply creation: Incoming tx, then contract command
+	account, err := InitialAccount().WithIncomingTx(creationTx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err = account.WithContractCommand(creationTx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vesting, ok := account.(*VestingContract)
+	if !ok {
+		t.Fatalf("Contract creation created %s.", account.Type())
+	}
+	if vesting.Balance != 1000 || vesting.Owner != owner || vesting.VestingStepAmount != 100 {
+		t.Fatalf("Invalid vesting contract created: %+v", vesting)
+	}
+
+	// Contracts can't receive funds
+	if _, err = vesting.WithIncomingTx(creationTx, 2); err != ErrAccount_IllegalIncomingTx {
+		t.Fatal("Contract accepted incoming tx.")
+	}
+
+	// Withdraw unlocked funds
+	withdrawTx := &ExtendedTx{
+		Data: []byte{},
+		Sender: creationTx.Recipient,
+		SenderType: VestingAccount,
+		Recipient: owner,
+		RecipientType: BasicAccount,
+		Value: 200,
+		ValidityStartHeight: 20,
+		NetworkId: 42,
+	}
+	withdrawTx.Proof = testSignatureProof(withdrawTx.SerializeContent()).Bytes()
+
+	if err = withdrawTx.Verify(42); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = vesting.WithOutgoingTx(withdrawTx, 20, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only 100 unlocked at height 10
+	withdrawTx.ValidityStartHeight = 10
+	withdrawTx.Proof = testSignatureProof(withdrawTx.SerializeContent()).Bytes()
+	if _, err = vesting.WithOutgoingTx(withdrawTx, 10, nil); err != ErrAccount_FundsLocked {
+		t.Fatal("Withdrawal of locked funds accepted.")
+	}
+
+	// Revert creation: Contract command, then incoming tx
+	account, err = vesting.RevertContractCommand(creationTx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Type() != BasicAccount || account.GetBalance() != 1000 {
+		t.Fatal("Revert of contract creation failed.")
+	}
+	account, err = account.RevertIncomingTx(creationTx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(AccountBytes(account), AccountBytes(InitialAccount())) {
+		t.Fatal("Revert did not restore initial account.")
+	}
+}
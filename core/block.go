@@ -0,0 +1,174 @@
+package core
+
+import (
+	"io"
+	"time"
+	"encoding/binary"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+	"github.com/terorie/go-nimiq/policy"
+)
+
+// A block with header and interlink.
+// Body is nil for light blocks.
+type Block struct {
+	Header BlockHeader
+	Interlink BlockInterlink
+	Body *BlockBody
+}
+
+// Max seconds a block timestamp may be ahead of the local clock
+const BlockTimestampDriftMax = 600
+
+// Source of the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// Clock of the local system
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (b *Block) IsFull() bool {
+	return b.Body != nil
+}
+
+func (b *Block) Hash() Hash {
+	return b.Header.Hash()
+}
+
+func (b *Block) SerializedSize() int {
+	size := BlockHeaderSize +
+		b.Interlink.SerializedSize() +
+		1 // Body present
+	if b.Body != nil {
+		size += b.Body.SerializedSize()
+	}
+	return size
+}
+
+// Wire format: header, interlink, body present (uint8), body
+func (b *Block) Deserialize(r io.Reader) error {
+	err := b.Header.Deserialize(r)
+	if err != nil { return err }
+
+	err = b.Interlink.Deserialize(r, b.Header.PrevHash)
+	if err != nil { return err }
+
+	var hasBody uint8
+	err = binary.Read(r, binary.BigEndian, &hasBody)
+	if err != nil { return err }
+
+	if hasBody != 0 {
+		b.Body = new(BlockBody)
+		return b.Body.Deserialize(r)
+	} else {
+		b.Body = nil
+		return nil
+	}
+}
+
+func (b *Block) Serialize(w io.Writer) error {
+	err := b.Header.Serialize(w)
+	if err != nil { return err }
+
+	err = b.Interlink.Serialize(w)
+	if err != nil { return err }
+
+	if b.Body == nil {
+		_, err = w.Write([]byte{0})
+		return err
+	}
+
+	_, err = w.Write([]byte{1})
+	if err != nil { return err }
+
+	return b.Body.Serialize(w)
+}
+
+func (b *Block) Bytes() []byte {
+	return bu.Serialize(b.SerializedSize(), b.Serialize)
+}
+
+// Static block verification: Checks that don't
+// require knowledge of the chain (predecessors, accounts).
+// Returns a BlockError for the failed rule, body
+// errors are a BodyError or a *BlockTxError.
+// genesisHash is the hash of the genesis block of the network.
+func (b *Block) Verify(clock Clock, networkId NetworkId, genesisHash Hash) error {
+	// Timestamp must not be too far in the future
+	maxTimestamp := clock.Now().Unix() + BlockTimestampDriftMax
+	if int64(b.Header.Timestamp) > maxTimestamp {
+		return ErrBlock_TimestampDrift
+	}
+
+	if b.Header.Version != BlockHeaderVersion {
+		return ErrBlock_UnsupportedVersion
+	}
+
+	if !IsValidCompact(b.Header.NBits) {
+		return ErrBlock_InvalidTarget
+	}
+
+	if !b.Header.VerifyProofOfWork() {
+		return ErrBlock_InvalidProofOfWork
+	}
+
+	if b.SerializedSize() > policy.BlockSizeMax {
+		return ErrBlock_TooLarge
+	}
+
+	// The genesis block has a zero interlink hash
+	isGenesis := b.Header.Height == 1 && b.Header.InterlinkHash == Hash{}
+	if !isGenesis && b.Interlink.Hash(genesisHash) != b.Header.InterlinkHash {
+		return ErrBlock_InterlinkHashMismatch
+	}
+
+	if b.Body != nil {
+		err := b.Body.Verify(networkId)
+		if err != nil { return err }
+
+		if b.Body.Hash() != b.Header.BodyHash {
+			return ErrBlock_BodyHashMismatch
+		}
+	}
+
+	return nil
+}
+
+// Error codes
+type BlockError uint8
+
+const (
+	_ = BlockError(iota)
+	ErrBlock_TimestampDrift
+	ErrBlock_UnsupportedVersion
+	ErrBlock_InvalidTarget
+	ErrBlock_InvalidProofOfWork
+	ErrBlock_TooLarge
+	ErrBlock_InterlinkHashMismatch
+	ErrBlock_BodyHashMismatch
+)
+
+func (b BlockError) Error() string {
+	switch b {
+	case ErrBlock_TimestampDrift:
+		return "block error: timestamp too far in the future"
+	case ErrBlock_UnsupportedVersion:
+		return "block error: unsupported version"
+	case ErrBlock_InvalidTarget:
+		return "block error: invalid target"
+	case ErrBlock_InvalidProofOfWork:
+		return "block error: invalid proof of work"
+	case ErrBlock_TooLarge:
+		return "block error: block too large"
+	case ErrBlock_InterlinkHashMismatch:
+		return "block error: interlink hash mismatch"
+	case ErrBlock_BodyHashMismatch:
+		return "block error: body hash mismatch"
+	default:
+		return ""
+	}
+}
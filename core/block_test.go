@@ -0,0 +1,189 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+	"time"
+	"github.com/terorie/go-nimiq/storage"
+)
+
+type testClock time.Time
+
+func (c testClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// Full block meeting the max target (mined for the test)
+func newTestBlock() *Block {
+	b := &Block{
+		Interlink: BlockInterlink{ Hashes: []Hash{ {0xfe} }, PrevHash: Hash{0x01} },
+		Body: newTestBlockBody(),
+	}
+	b.Header = BlockHeader{
+		Version: BlockHeaderVersion,
+		PrevHash: Hash{0x01},
+		InterlinkHash: b.Interlink.Hash(testGenesisHash),
+		BodyHash: b.Body.Hash(),
+		AccountsHash: Hash{0x04},
+		NBits: 0x1f010000,
+		Height: 2,
+		Timestamp: 1523727060,
+		Nonce: testBlockNonce,
+	}
+	return b
+}
+
+const testBlockNonce = 58812
+
+var testGenesisHash = Hash{0xfe}
+
+var testBlockClock = testClock(time.Unix(1523727060, 0))
+
+func TestBlock_Serialize(t *testing.T) {
+	b := newTestBlock()
+
+	buf := b.Bytes()
+	if len(buf) != b.SerializedSize() {
+		t.Fatalf("Invalid serialized size: %d", len(buf))
+	}
+
+	var b2 Block
+	if err := b2.Deserialize(bytes.NewReader(buf)); err != nil {
+		t.Fatal(err)
+	}
+	if b2.Hash() != b.Hash() || !b2.IsFull() || b2.Body.Hash() != b.Body.Hash() {
+		t.Fatal("Block changed after serialization")
+	}
+
+	// Light block
+	b.Body = nil
+	if err := b2.Deserialize(bytes.NewReader(b.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if b2.IsFull() {
+		t.Fatal("Light block has body")
+	}
+}
+
+func TestBlock_Verify(t *testing.T) {
+	if err := newTestBlock().Verify(testBlockClock, 42, testGenesisHash); err != nil {
+		t.Fatalf("Valid block rejected: %s", err)
+	}
+
+	// Light block
+	b := newTestBlock()
+	b.Body = nil
+	if err := b.Verify(testBlockClock, 42, testGenesisHash); err != nil {
+		t.Fatalf("Valid light block rejected: %s", err)
+	}
+
+	past := testClock(time.Unix(1523727060 - BlockTimestampDriftMax - 1, 0))
+	if err := newTestBlock().Verify(past, 42, testGenesisHash); err != ErrBlock_TimestampDrift {
+		t.Fatal("Block from the future accepted.")
+	}
+
+	b = newTestBlock()
+	b.Header.NBits = 0x1f7fffff
+	if err := b.Verify(testBlockClock, 42, testGenesisHash); err != ErrBlock_InvalidTarget {
+		t.Fatal("Target above max accepted.")
+	}
+
+	b = newTestBlock()
+	b.Header.Nonce++
+	if err := b.Verify(testBlockClock, 42, testGenesisHash); err != ErrBlock_InvalidProofOfWork {
+		t.Fatal("Invalid PoW accepted.")
+	}
+
+	b = newTestBlock()
+	for b.SerializedSize() <= 100000 {
+		b.Body.Txs = append(b.Body.Txs, b.Body.Txs[0])
+	}
+	if err := b.Verify(testBlockClock, 42, testGenesisHash); err != ErrBlock_TooLarge {
+		t.Fatal("Oversized block accepted.")
+	}
+
+	b = newTestBlock()
+	b.Interlink.Hashes = append(b.Interlink.Hashes, Hash{0x02})
+	if err := b.Verify(testBlockClock, 42, testGenesisHash); err != ErrBlock_InterlinkHashMismatch {
+		t.Fatal("Wrong interlink accepted.")
+	}
+
+	b = newTestBlock()
+	b.Body.ExtraData = []byte("modified")
+	if err := b.Verify(testBlockClock, 42, testGenesisHash); err != ErrBlock_BodyHashMismatch {
+		t.Fatal("Wrong body accepted.")
+	}
+
+	b = newTestBlock()
+	b.Body.Txs[0], b.Body.Txs[1] = b.Body.Txs[1], b.Body.Txs[0]
+	if err := b.Verify(testBlockClock, 42, testGenesisHash); err != ErrBody_TxsNotOrdered {
+		t.Fatal("Invalid body accepted.")
+	}
+}
+
+func TestBlock_VerifyGenesis(t *testing.T) {
+	g := loadMainGenesis(t)
+	b := &Block{ Header: g.Header, Body: &g.Body }
+	clock := testClock(time.Unix(int64(b.Header.Timestamp), 0))
+
+	// Zero interlink hash of the genesis block is accepted
+	if err := b.Verify(clock, NetworkMain, g.Hash()); err != nil {
+		t.Fatalf("Main genesis rejected: %s", err)
+	}
+}
+
+func TestPutBlock(t *testing.T) {
+	db := storage.NewMemoryDB()
+	block := newTestBlock()
+
+	b := new(storage.Batch)
+	PutBlock(b, block)
+	if err := db.Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	block2, err := GetBlock(db, block.Hash())
+	if err != nil { t.Fatal(err) }
+	if block2.Hash() != block.Hash() || block2.Body.Hash() != block.Body.Hash() {
+		t.Fatal("Stored block differs")
+	}
+
+	if _, err := GetBlock(db, Hash{}); err != storage.ErrNotFound {
+		t.Fatal("Unknown block found")
+	}
+}
+
+func TestPutHead(t *testing.T) {
+	db := storage.NewMemoryDB()
+	block := newTestBlock()
+
+	if _, err := GetHead(db); err != storage.ErrNotFound {
+		t.Fatal("Head of empty chain found")
+	}
+
+	b := new(storage.Batch)
+	PutBlock(b, block)
+	PutHead(b, block)
+	if err := db.Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if head, err := GetHead(db); err != nil || head != block.Hash() {
+		t.Fatal("Invalid head")
+	}
+	if block2, err := GetBlockAt(db, block.Header.Height); err != nil || block2.Hash() != block.Hash() {
+		t.Fatal("Block not indexed by height")
+	}
+
+	b.Reset()
+	RevertHead(b, block)
+	if err := db.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if head, err := GetHead(db); err != nil || head != block.Header.PrevHash {
+		t.Fatal("Head not reverted")
+	}
+	if _, err := GetBlockAt(db, block.Header.Height); err != storage.ErrNotFound {
+		t.Fatal("Reverted block still indexed")
+	}
+}
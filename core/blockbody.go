@@ -0,0 +1,291 @@
+package core
+
+import (
+	"io"
+	"bytes"
+	"errors"
+	"fmt"
+	"encoding/binary"
+	"github.com/terorie/go-nimiq/merkle"
+)
+
+// Contents of a full block:
+// The miner, txs and accounts pruned by the block
+type BlockBody struct {
+	MinerAddr Address
+	ExtraData []byte
+	Txs []Tx
+	// Contract accounts emptied by the txs
+	// (removed from the accounts tree)
+	PrunedAccounts []PrunedAccount
+}
+
+// Account removed from the accounts tree
+type PrunedAccount struct {
+	Address Address
+	Account Account
+}
+
+var ErrBlockBodyFieldTooLong = errors.New("block body field exceeds length prefix")
+
+func (b *BlockBody) SerializedSize() int {
+	size := 20 + // MinerAddr
+		1 + len(b.ExtraData) +
+		2 // Tx count
+	for _, tx := range b.Txs {
+		size += tx.SerializedSize()
+	}
+	size += 2 // Pruned account count
+	for _, acc := range b.PrunedAccounts {
+		size += 20 + acc.Account.SerializedSize()
+	}
+	return size
+}
+
+func (b *BlockBody) Deserialize(r io.Reader) error {
+	bo := binary.BigEndian
+	var err error
+
+	// Read miner address
+	_, err = io.ReadFull(r, b.MinerAddr[:])
+	if err != nil { return err }
+
+	// Read extra data
+	var extraDataLen uint8
+	err = binary.Read(r, bo, &extraDataLen)
+	if err != nil { return err }
+
+	b.ExtraData = make([]byte, extraDataLen)
+	_, err = io.ReadFull(r, b.ExtraData)
+	if err != nil { return err }
+
+	// Read txs
+	var txCount uint16
+	err = binary.Read(r, bo, &txCount)
+	if err != nil { return err }
+
+	b.Txs = make([]Tx, txCount)
+	for i := range b.Txs {
+		b.Txs[i], err = DeserializeTx(r)
+		if err != nil { return err }
+	}
+
+	// Read pruned accounts
+	var prunedCount uint16
+	err = binary.Read(r, bo, &prunedCount)
+	if err != nil { return err }
+
+	b.PrunedAccounts = make([]PrunedAccount, prunedCount)
+	for i := range b.PrunedAccounts {
+		acc := &b.PrunedAccounts[i]
+		_, err = io.ReadFull(r, acc.Address[:])
+		if err != nil { return err }
+
+		acc.Account, err = DeserializeAccount(r)
+		if err != nil { return err }
+	}
+
+	// No error
+	return nil
+}
+
+func (b *BlockBody) Serialize(w io.Writer) error {
+	bo := binary.BigEndian
+	var err error
+
+	if len(b.ExtraData) > 0xFF ||
+		len(b.Txs) > 0xFFFF ||
+		len(b.PrunedAccounts) > 0xFFFF {
+		return ErrBlockBodyFieldTooLong
+	}
+
+	// Write miner address
+	_, err = w.Write(b.MinerAddr[:])
+	if err != nil { return err }
+
+	// Write extra data
+	err = binary.Write(w, bo, uint8(len(b.ExtraData)))
+	if err != nil { return err }
+
+	_, err = w.Write(b.ExtraData)
+	if err != nil { return err }
+
+	// Write txs
+	err = binary.Write(w, bo, uint16(len(b.Txs)))
+	if err != nil { return err }
+
+	for _, tx := range b.Txs {
+		err = SerializeTx(w, tx)
+		if err != nil { return err }
+	}
+
+	// Write pruned accounts
+	err = binary.Write(w, bo, uint16(len(b.PrunedAccounts)))
+	if err != nil { return err }
+
+	for _, acc := range b.PrunedAccounts {
+		_, err = w.Write(acc.Address[:])
+		if err != nil { return err }
+
+		err = SerializeAccount(w, acc.Account)
+		if err != nil { return err }
+	}
+
+	// No error
+	return nil
+}
+
+// Merkle root over the miner address, extra data,
+// tx hashes and pruned accounts
+func (b *BlockBody) Hash() Hash {
+	leaves := make([]merkle.Leaf, 0, 2 + len(b.Txs) + len(b.PrunedAccounts))
+	leaves = append(leaves, merkle.BytesLeaf(b.MinerAddr[:]))
+	leaves = append(leaves, merkle.BytesLeaf(b.ExtraData))
+	for _, tx := range b.Txs {
+		leaves = append(leaves, merkle.HashLeaf(tx.Hash()))
+	}
+	for _, acc := range b.PrunedAccounts {
+		leaves = append(leaves, merkle.HashLeaf(acc.Hash()))
+	}
+	return merkle.ComputeRoot(leaves)
+}
+
+// Invalid tx in a block body
+type BlockTxError struct {
+	// Position of the tx in the body
+	Index int
+	Err error
+}
+
+func (e *BlockTxError) Error() string {
+	return fmt.Sprintf("block body error: tx %d: %s", e.Index, e.Err)
+}
+
+func (e *BlockTxError) Unwrap() error {
+	return e.Err
+}
+
+// Checks the txs and pruned accounts:
+// Both must be valid, sorted and without duplicates
+func (b *BlockBody) Verify(networkId NetworkId) error {
+	if len(b.ExtraData) > 0xFF {
+		return ErrBody_ExtraDataTooLong
+	}
+
+	var prevTx Tx
+	for i, tx := range b.Txs {
+		// Ascending block order also rules out duplicates
+		if prevTx != nil && CompareTxBlockOrder(prevTx, tx) >= 0 {
+			return ErrBody_TxsNotOrdered
+		}
+		prevTx = tx
+
+		err := tx.Verify(networkId)
+		if err != nil {
+			return &BlockTxError{ Index: i, Err: err }
+		}
+	}
+
+	for i, acc := range b.PrunedAccounts {
+		if i > 0 && bytes.Compare(b.PrunedAccounts[i-1].Address[:], acc.Address[:]) >= 0 {
+			return ErrBody_PrunedAccountsNotOrdered
+		}
+
+		if !acc.IsToBePruned() {
+			return ErrBody_AccountNotPrunable
+		}
+	}
+
+	return nil
+}
+
+func (p *PrunedAccount) Hash() Hash {
+	buf := make([]byte, 20, 20 + p.Account.SerializedSize())
+	copy(buf, p.Address[:])
+	buf = append(buf, AccountBytes(p.Account)...)
+	return Blake2bHash(buf)
+}
+
+// Only empty contracts get pruned,
+// basic accounts without balance are initial
+func (p *PrunedAccount) IsToBePruned() bool {
+	return p.Account.GetBalance() == 0 && !p.Account.IsInitial()
+}
+
+// Order of txs in a block: Grouped by recipient,
+// higher fee and value first
+func CompareTxBlockOrder(a, b Tx) int {
+	x, y := a.ToExtended(), b.ToExtended()
+
+	if c := bytes.Compare(x.Recipient[:], y.Recipient[:]); c != 0 {
+		return c
+	}
+	if x.ValidityStartHeight != y.ValidityStartHeight {
+		if x.ValidityStartHeight < y.ValidityStartHeight {
+			return -1
+		}
+		return 1
+	}
+	// Descending
+	if x.Fee != y.Fee {
+		if x.Fee > y.Fee {
+			return -1
+		}
+		return 1
+	}
+	// Descending
+	if x.Value != y.Value {
+		if x.Value > y.Value {
+			return -1
+		}
+		return 1
+	}
+	if c := bytes.Compare(x.Sender[:], y.Sender[:]); c != 0 {
+		return c
+	}
+	if x.RecipientType != y.RecipientType {
+		if x.RecipientType < y.RecipientType {
+			return -1
+		}
+		return 1
+	}
+	if x.SenderType != y.SenderType {
+		if x.SenderType < y.SenderType {
+			return -1
+		}
+		return 1
+	}
+	if x.Flags != y.Flags {
+		if x.Flags < y.Flags {
+			return -1
+		}
+		return 1
+	}
+	return bytes.Compare(x.Data, y.Data)
+}
+
+// Error codes
+type BodyError uint8
+
+const (
+	_ = BodyError(iota)
+	ErrBody_ExtraDataTooLong
+	ErrBody_TxsNotOrdered
+	ErrBody_PrunedAccountsNotOrdered
+	ErrBody_AccountNotPrunable
+)
+
+func (b BodyError) Error() string {
+	switch b {
+	case ErrBody_ExtraDataTooLong:
+		return "block body error: extra data too long"
+	case ErrBody_TxsNotOrdered:
+		return "block body error: txs not ordered or duplicate"
+	case ErrBody_PrunedAccountsNotOrdered:
+		return "block body error: pruned accounts not ordered or duplicate"
+	case ErrBody_AccountNotPrunable:
+		return "block body error: pruned account is not empty"
+	default:
+		return ""
+	}
+}
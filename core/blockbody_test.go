@@ -0,0 +1,119 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+	"errors"
+)
+
+func newTestBlockBody() *BlockBody {
+	newTx := func(recipient byte, fee Satoshi) *BasicTx {
+		tx := &BasicTx{
+			Recipient: Address{recipient},
+			Value: 100,
+			Fee: fee,
+			ValidityStartHeight: 1000,
+			NetworkId: 42,
+		}
+		tx.Sign(&testPrivateKey)
+		return tx
+	}
+
+	return &BlockBody{
+		MinerAddr: Address{0xaa},
+		ExtraData: []byte("go-nimiq"),
+		Txs: []Tx{
+			newTx(0x01, 2),
+			newTx(0x01, 1),
+			newTx(0x02, 1).ToExtended(),
+		},
+		PrunedAccounts: []PrunedAccount{
+			{ Address{0x10}, &HtlcContract{ HashAlgorithm: HashAlgorithmBlake2b } },
+			{ Address{0x11}, &VestingContract{} },
+		},
+	}
+}
+
+func TestBlockBody_Serialize(t *testing.T) {
+	b := newTestBlockBody()
+
+	var buf bytes.Buffer
+	if err := b.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != b.SerializedSize() {
+		t.Fatalf("Invalid serialized size: %d", buf.Len())
+	}
+
+	var b2 BlockBody
+	if err := b2.Deserialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatal("Trailing bytes")
+	}
+	if len(b2.Txs) != 3 || len(b2.PrunedAccounts) != 2 {
+		t.Fatal("Invalid tx or pruned account count")
+	}
+	if b2.Txs[0].Format() != TxFormatBasic || b2.Txs[2].Format() != TxFormatExtended {
+		t.Fatal("Tx formats not preserved")
+	}
+	if b2.Hash() != b.Hash() {
+		t.Fatal("Body hash mismatch")
+	}
+}
+
+func TestBlockBody_Hash(t *testing.T) {
+	b := &BlockBody{ MinerAddr: Address{0xaa}, ExtraData: []byte{} }
+
+	var concat [64]byte
+	left, right := Blake2bHash(b.MinerAddr[:]), Blake2bHash(nil)
+	copy(concat[:32], left[:])
+	copy(concat[32:], right[:])
+	if b.Hash() != Blake2bHash(concat[:]) {
+		t.Fatal("Invalid body hash")
+	}
+
+	// The body hash commits to the tx order
+	b = newTestBlockBody()
+	h := b.Hash()
+	b.Txs[0], b.Txs[1] = b.Txs[1], b.Txs[0]
+	if b.Hash() == h {
+		t.Fatal("Body hash ignores tx order")
+	}
+}
+
+func TestBlockBody_Verify(t *testing.T) {
+	if err := newTestBlockBody().Verify(42); err != nil {
+		t.Fatalf("Valid body rejected: %s", err)
+	}
+
+	b := newTestBlockBody()
+	b.Txs[0], b.Txs[1] = b.Txs[1], b.Txs[0]
+	if err := b.Verify(42); err != ErrBody_TxsNotOrdered {
+		t.Fatal("Unordered txs accepted.")
+	}
+
+	b = newTestBlockBody()
+	b.Txs[1] = b.Txs[0]
+	if err := b.Verify(42); err != ErrBody_TxsNotOrdered {
+		t.Fatal("Duplicate tx accepted.")
+	}
+
+	b = newTestBlockBody()
+	b.PrunedAccounts[1].Address = b.PrunedAccounts[0].Address
+	if err := b.Verify(42); err != ErrBody_PrunedAccountsNotOrdered {
+		t.Fatal("Duplicate pruned account accepted.")
+	}
+
+	b = newTestBlockBody()
+	b.PrunedAccounts[0].Account = &BasicWallet{}
+	if err := b.Verify(42); err != ErrBody_AccountNotPrunable {
+		t.Fatal("Initial account pruned.")
+	}
+
+	err := newTestBlockBody().Verify(1)
+	if txErr, ok := err.(*BlockTxError); !ok || txErr.Index != 0 || !errors.Is(err, ErrTx_WrongNetwork) {
+		t.Fatal("Tx of other network accepted.")
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"io"
+	"errors"
+	"math/big"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+)
+
+type BlockHeader struct {
+	Version uint16
+	PrevHash Hash
+	InterlinkHash Hash
+	BodyHash Hash
+	AccountsHash Hash
+	NBits uint32
+	Height uint32
+	Timestamp uint32
+	Nonce uint32
+}
+
+const BlockHeaderSize =
+	2 +  // Version
+	32 + // PrevHash
+	32 + // InterlinkHash
+	32 + // BodyHash
+	32 + // AccountsHash
+	4 +  // NBits
+	4 +  // Height
+	4 +  // Timestamp
+	4    // Nonce
+
+const BlockHeaderVersion = 1
+
+var ErrUnsupportedBlockVersion = errors.New("unsupported block version")
+
+func (h *BlockHeader) Deserialize(r io.Reader) error {
+	var buf [BlockHeaderSize]byte
+	_, err := io.ReadFull(r, buf[:])
+	if err != nil { return err }
+
+	sl := bu.ReadBytes(buf[:])
+
+	h.Version = sl.Uint16()
+	if h.Version != BlockHeaderVersion {
+		return ErrUnsupportedBlockVersion
+	}
+
+	sl.CopyNext(h.PrevHash[:])
+	sl.CopyNext(h.InterlinkHash[:])
+	sl.CopyNext(h.BodyHash[:])
+	sl.CopyNext(h.AccountsHash[:])
+	h.NBits = sl.Uint32()
+	h.Height = sl.Uint32()
+	h.Timestamp = sl.Uint32()
+	h.Nonce = sl.Uint32()
+
+	return nil
+}
+
+func (h *BlockHeader) Serialize(w io.Writer) error {
+	buf := h.Bytes()
+	_, err := w.Write(buf[:])
+	return err
+}
+
+func (h *BlockHeader) Bytes() (buf [BlockHeaderSize]byte) {
+	sl := bu.WriteBytes(buf[:])
+
+	sl.Uint16(h.Version)
+	sl.WriteNext(h.PrevHash[:])
+	sl.WriteNext(h.InterlinkHash[:])
+	sl.WriteNext(h.BodyHash[:])
+	sl.WriteNext(h.AccountsHash[:])
+	sl.Uint32(h.NBits)
+	sl.Uint32(h.Height)
+	sl.Uint32(h.Timestamp)
+	sl.Uint32(h.Nonce)
+
+	return
+}
+
+// Block hash: Blake2b of the header
+func (h *BlockHeader) Hash() Hash {
+	buf := h.Bytes()
+	return Blake2bHash(buf[:])
+}
+
+// Proof-of-work hash: Argon2d of the header
+func (h *BlockHeader) PowHash() Hash {
+	buf := h.Bytes()
+	return Argon2dHash(buf[:])
+}
+
+// Full target decoded from NBits
+func (h *BlockHeader) Target() *big.Int {
+	return CompactToTarget(h.NBits)
+}
+
+func (h *BlockHeader) Difficulty() *big.Float {
+	return CompactToDifficulty(h.NBits)
+}
+
+// Checks if the PoW hash meets the target
+func (h *BlockHeader) VerifyProofOfWork() bool {
+	return IsProofOfWork(h.PowHash(), h.Target())
+}
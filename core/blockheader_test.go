@@ -0,0 +1,106 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+)
+
+func newTestBlockHeader() *BlockHeader {
+	return &BlockHeader{
+		Version: 1,
+		PrevHash: Hash{0x01},
+		InterlinkHash: Hash{0x02},
+		BodyHash: Hash{0x03},
+		AccountsHash: Hash{0x04},
+		NBits: 0x1f7fffff,
+		Height: 2,
+		Timestamp: 1523727060,
+		Nonce: 547,
+	}
+}
+
+func TestBlockHeader_Serialize(t *testing.T) {
+	h := newTestBlockHeader()
+
+	var buf bytes.Buffer
+	if err := h.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() != BlockHeaderSize {
+		t.Fatalf("Invalid serialized size: %d", buf.Len())
+	}
+
+	raw := buf.Bytes()
+	// Version, first byte of PrevHash, NBits ... Nonce
+	if !bytes.Equal(raw[:3], []byte{0x00, 0x01, 0x01}) ||
+		!bytes.Equal(raw[130:], []byte{
+			0x1f, 0x7f, 0xff, 0xff,
+			0x00, 0x00, 0x00, 0x02,
+			0x5a, 0xd2, 0x3a, 0xd4,
+			0x00, 0x00, 0x02, 0x23,
+		}) {
+		t.Fatalf("Failed serializing header: hex(%x)", raw)
+	}
+
+	var h2 BlockHeader
+	if err := h2.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+
+	if h2 != *h {
+		t.Fatalf("Failed deserializing header: %+v", h2)
+	}
+
+	raw[1] = 2
+	if err := h2.Deserialize(bytes.NewReader(raw)); err != ErrUnsupportedBlockVersion {
+		t.Fatal("Unsupported version accepted.")
+	}
+}
+
+func TestBlockHeader_Hash(t *testing.T) {
+	hash := Hash{
+		0x30, 0x1f, 0x68, 0x73, 0x14, 0xeb, 0xc4, 0x0e, 0x89, 0xbc, 0x16, 0x55, 0x42, 0xfb, 0x80, 0x19,
+		0xc7, 0x96, 0xac, 0x6d, 0x8e, 0xdf, 0x1c, 0x47, 0x7e, 0x88, 0xf6, 0xff, 0x75, 0x74, 0x13, 0xfc,
+	}
+
+	if h := newTestBlockHeader().Hash(); h != hash {
+		t.Fatalf("Invalid header hash.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			hash, h,
+		)
+	}
+}
+
+func TestBlockHeader_VerifyProofOfWork(t *testing.T) {
+	h := newTestBlockHeader()
+
+	// Argon2d hash starts with 0x0067f5e7
+	if !h.VerifyProofOfWork() {
+		t.Fatalf("Valid PoW rejected: hex(%x)", h.PowHash())
+	}
+
+	// Target 2^240
+	h.NBits = 0x1f010000
+	if h.VerifyProofOfWork() {
+		t.Fatal("Insufficient PoW accepted.")
+	}
+
+	h.NBits = 0x1f7fffff
+	h.Nonce++
+	if h.VerifyProofOfWork() {
+		t.Fatal("Invalid nonce accepted.")
+	}
+}
+
+func TestCompactToTarget(t *testing.T) {
+	target := CompactToTarget(0x1f010000)
+	if target.BitLen() != 241 || target.TrailingZeroBits() != 240 {
+		t.Fatalf("Invalid target: %x", target)
+	}
+
+	if CompactToTarget(0x02123456).Int64() != 0x1234 {
+		t.Fatal("Invalid target of small compact.")
+	}
+}
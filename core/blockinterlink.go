@@ -0,0 +1,150 @@
+package core
+
+import (
+	"io"
+	"errors"
+	"math/big"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+	"github.com/terorie/go-nimiq/merkle"
+)
+
+var ErrInterlinkTooLong = errors.New("interlink longer than 255 hashes")
+
+// Links to previous superblocks (NiPoPoW):
+// Hashes[i] is the latest block at depth > i
+// relative to the block target.
+type BlockInterlink struct {
+	Hashes []Hash
+	// Hash of the previous block, the first
+	// compressed hash is relative to it
+	PrevHash Hash
+}
+
+// Compression: Hashes equal to their predecessor
+// (or PrevHash for the first one) are replaced
+// by a set bit in the repeat bits (MSB first).
+func (i *BlockInterlink) compress() (repeatBits []byte, compressed []Hash) {
+	repeatBits = make([]byte, (len(i.Hashes) + 7) / 8)
+	lastHash := i.PrevHash
+	for j, hash := range i.Hashes {
+		if hash != lastHash {
+			compressed = append(compressed, hash)
+			lastHash = hash
+		} else {
+			repeatBits[j / 8] |= 0x80 >> uint(j % 8)
+		}
+	}
+	return
+}
+
+func (i *BlockInterlink) SerializedSize() int {
+	repeatBits, compressed := i.compress()
+	return 1 + len(repeatBits) + 32 * len(compressed)
+}
+
+// Wire format: count (uint8), repeat bits, compressed hashes
+func (i *BlockInterlink) Serialize(w io.Writer) error {
+	if len(i.Hashes) > 0xFF {
+		return ErrInterlinkTooLong
+	}
+
+	repeatBits, compressed := i.compress()
+
+	buf := make([]byte, 1 + len(repeatBits) + 32 * len(compressed))
+	sl := bu.WriteBytes(buf)
+
+	sl.Uint8(uint8(len(i.Hashes)))
+	for _, b := range repeatBits {
+		sl.Uint8(b)
+	}
+	for _, hash := range compressed {
+		sl.WriteNext(hash[:])
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// Reads an interlink of the block with the given prevHash
+func (i *BlockInterlink) Deserialize(r io.Reader, prevHash Hash) error {
+	var count [1]byte
+	_, err := io.ReadFull(r, count[:])
+	if err != nil { return err }
+
+	repeatBits := make([]byte, (int(count[0]) + 7) / 8)
+	_, err = io.ReadFull(r, repeatBits)
+	if err != nil { return err }
+
+	// Count set bits to know how many hashes follow
+	repeated := 0
+	for j := 0; j < int(count[0]); j++ {
+		if repeatBits[j / 8] & (0x80 >> uint(j % 8)) != 0 {
+			repeated++
+		}
+	}
+
+	buf := make([]byte, 32 * (int(count[0]) - repeated))
+	_, err = io.ReadFull(r, buf)
+	if err != nil { return err }
+
+	sl := bu.ReadBytes(buf)
+
+	i.PrevHash = prevHash
+	i.Hashes = make([]Hash, count[0])
+	lastHash := prevHash
+	for j := range i.Hashes {
+		if repeatBits[j / 8] & (0x80 >> uint(j % 8)) == 0 {
+			sl.CopyNext(lastHash[:])
+		}
+		i.Hashes[j] = lastHash
+	}
+
+	return nil
+}
+
+// Merkle root over the repeat bits, the genesis
+// hash of the chain and the compressed hashes
+func (i *BlockInterlink) Hash(genesisHash Hash) Hash {
+	repeatBits, compressed := i.compress()
+
+	leaves := make([]merkle.Leaf, 0, 2 + len(compressed))
+	leaves = append(leaves, merkle.BytesLeaf(repeatBits))
+	leaves = append(leaves, merkle.HashLeaf(genesisHash))
+	for _, hash := range compressed {
+		leaves = append(leaves, merkle.HashLeaf(hash))
+	}
+
+	return merkle.ComputeRoot(leaves)
+}
+
+// Computes the interlink of the successor of the block
+// with the header h and the interlink i, given the
+// target of the next block.
+func (h *BlockHeader) NextInterlink(i *BlockInterlink, nextTarget *big.Int) *BlockInterlink {
+	hash := h.Hash()
+
+	// This block is the latest block up to
+	// the depth of its PoW hash (relative to the next target)
+	nextTargetDepth := TargetDepth(nextTarget)
+	occurrences := HashDepth(h.PowHash()) - nextTargetDepth + 1
+	if occurrences < 0 {
+		occurrences = 0
+	}
+
+	hashes := make([]Hash, 0, occurrences + len(i.Hashes))
+	for j := 0; j < occurrences; j++ {
+		hashes = append(hashes, hash)
+	}
+
+	// Carry over the remaining levels of the current
+	// interlink, shifted by the change of the target depth
+	offset := occurrences + nextTargetDepth - TargetDepth(h.Target())
+	if offset < 0 {
+		offset = 0
+	}
+	for j := offset; j < len(i.Hashes); j++ {
+		hashes = append(hashes, i.Hashes[j])
+	}
+
+	return &BlockInterlink{ Hashes: hashes, PrevHash: hash }
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+	"math/big"
+)
+
+func TestBlockInterlink_Serialize(t *testing.T) {
+	prevHash := Hash{0x01}
+	i := BlockInterlink{
+		Hashes: []Hash{ {0x0a}, prevHash, prevHash, {0x0b}, {0x0b} },
+		PrevHash: prevHash,
+	}
+
+	var buf bytes.Buffer
+	if err := i.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Count, repeat bits, 3 compressed hashes
+	if buf.Len() != 1 + 1 + 3 * 32 || buf.Len() != i.SerializedSize() {
+		t.Fatalf("Invalid serialized size: %d", buf.Len())
+	}
+	if raw := buf.Bytes(); raw[0] != 5 || raw[1] != 0x28 {
+		t.Fatalf("Invalid header: %x", raw[:2])
+	}
+
+	var i2 BlockInterlink
+	if err := i2.Deserialize(&buf, prevHash); err != nil {
+		t.Fatal(err)
+	}
+	if len(i2.Hashes) != len(i.Hashes) {
+		t.Fatalf("Invalid hash count: %d", len(i2.Hashes))
+	}
+	for j := range i.Hashes {
+		if i2.Hashes[j] != i.Hashes[j] {
+			t.Fatalf("Hash %d mismatch", j)
+		}
+	}
+	if i2.Hash(Hash{0xfe}) != i.Hash(Hash{0xfe}) {
+		t.Fatal("Interlink hash mismatch")
+	}
+}
+
+func TestBlockInterlink_Hash(t *testing.T) {
+	genesisHash := Hash{0xfe}
+
+	// Empty interlink: Leaves are the (empty) repeat bits and the genesis hash
+	var i BlockInterlink
+	empty := Blake2bHash(nil)
+	want := Blake2bHash(append(empty[:], genesisHash[:]...))
+	if i.Hash(genesisHash) != want {
+		t.Fatal("Invalid empty interlink hash")
+	}
+	if i.Hash(Hash{0xfd}) == want {
+		t.Fatal("Interlink hash doesn't commit to genesis hash")
+	}
+}
+
+func TestBlockHeader_NextInterlink(t *testing.T) {
+	h := newTestBlockHeader()
+	hash := h.Hash()
+	target := h.Target()
+	i := &BlockInterlink{ Hashes: []Hash{ {0x10}, {0x11}, {0x12}, {0x13} } }
+
+	// The PoW hash of the test header is at the depth of its target,
+	// so it replaces the first level
+	checkInterlink(t, h.NextInterlink(i, target), hash, []Hash{
+		hash, {0x11}, {0x12}, {0x13},
+	})
+
+	// Halving the target: The block is too shallow to be included
+	// and the interlink shifts up by a level
+	harder := new(big.Int).Rsh(target, 1)
+	checkInterlink(t, h.NextInterlink(i, harder), hash, []Hash{
+		{0x11}, {0x12}, {0x13},
+	})
+
+	// Doubling the target: The block covers two levels
+	easier := new(big.Int).Lsh(target, 1)
+	checkInterlink(t, h.NextInterlink(i, easier), hash, []Hash{
+		hash, hash, {0x11}, {0x12}, {0x13},
+	})
+}
+
+func checkInterlink(t *testing.T, i *BlockInterlink, prevHash Hash, hashes []Hash) {
+	t.Helper()
+	if i.PrevHash != prevHash {
+		t.Fatal("Invalid prev hash")
+	}
+	if len(i.Hashes) != len(hashes) {
+		t.Fatalf("Invalid interlink length: %d", len(i.Hashes))
+	}
+	for j := range hashes {
+		if i.Hashes[j] != hashes[j] {
+			t.Fatalf("Hash %d mismatch: %x", j, i.Hashes[j][:4])
+		}
+	}
+}
+
+func TestTargetHeight(t *testing.T) {
+	if TargetHeight(big.NewInt(1 << 10)) != 10 {
+		t.Fatal("Invalid height for power of two")
+	}
+	if TargetHeight(big.NewInt(1 << 10 + 1)) != 11 {
+		t.Fatal("Invalid height")
+	}
+}
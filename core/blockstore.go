@@ -0,0 +1,88 @@
+package core
+
+import (
+	"io"
+	"bytes"
+	"github.com/terorie/go-nimiq/storage"
+)
+
+// Key prefix of blocks in storage, followed by the block hash
+var blockKeyPrefix = []byte("block/")
+
+func blockKey(hash Hash) []byte {
+	key := make([]byte, 0, len(blockKeyPrefix) + len(hash))
+	key = append(key, blockKeyPrefix...)
+	return append(key, hash[:]...)
+}
+
+// Adds the block to the batch, keyed by its hash
+func PutBlock(b *storage.Batch, block *Block) {
+	b.Put(blockKey(block.Hash()), block.Bytes())
+}
+
+// Reads the block with the given hash.
+// Returns storage.ErrNotFound for unknown blocks.
+func GetBlock(r storage.Reader, hash Hash) (*Block, error) {
+	buf, err := r.Get(blockKey(hash))
+	if err != nil { return nil, err }
+
+	block := new(Block)
+	err = block.Deserialize(bytes.NewReader(buf))
+	if err != nil { return nil, err }
+
+	return block, nil
+}
+
+// Key of the hash of the chain head
+var headKey = []byte("head")
+
+// Key prefix of the main chain index,
+// followed by the height (uint32)
+var heightKeyPrefix = []byte("height/")
+
+func heightKey(height uint32) []byte {
+	key := make([]byte, 0, len(heightKeyPrefix) + 4)
+	key = append(key, heightKeyPrefix...)
+	return append(key, byte(height >> 24), byte(height >> 16), byte(height >> 8), byte(height))
+}
+
+// Adds the block as the new chain head to the batch
+func PutHead(b *storage.Batch, block *Block) {
+	hash := block.Hash()
+	b.Put(headKey, hash[:])
+	b.Put(heightKey(block.Header.Height), hash[:])
+}
+
+// Removes the head block from the chain in the batch,
+// its predecessor becomes the head
+func RevertHead(b *storage.Batch, block *Block) {
+	b.Put(headKey, block.Header.PrevHash[:])
+	b.Delete(heightKey(block.Header.Height))
+}
+
+// Reads the hash of the chain head.
+// Returns storage.ErrNotFound for an empty chain.
+func GetHead(r storage.Reader) (Hash, error) {
+	buf, err := r.Get(headKey)
+	if err != nil { return Hash{}, err }
+	return readHash(buf)
+}
+
+// Reads the block of the chain at height
+func GetBlockAt(r storage.Reader, height uint32) (*Block, error) {
+	buf, err := r.Get(heightKey(height))
+	if err != nil { return nil, err }
+
+	hash, err := readHash(buf)
+	if err != nil { return nil, err }
+
+	return GetBlock(r, hash)
+}
+
+func readHash(buf []byte) (hash Hash, err error) {
+	if len(buf) != len(hash) {
+		return hash, io.ErrUnexpectedEOF
+	}
+	copy(hash[:], buf)
+	return hash, nil
+}
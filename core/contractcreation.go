@@ -0,0 +1,125 @@
+package core
+
+import (
+	bu "github.com/terorie/go-nimiq/bufferutils"
+)
+
+// Contracts are created by an ExtendedTx with the
+// TxFlagContractCreation flag. The contract type is
+// RecipientType, its parameters are encoded in Data.
+
+// Parameters of a new vesting contract
+type VestingCreationData struct {
+	Owner Address
+	VestingStart uint32
+	VestingStepBlocks uint32
+	VestingStepAmount Satoshi
+	VestingTotalAmount Satoshi
+}
+
+// Parameters of a new HTLC
+type HtlcCreationData struct {
+	Sender Address
+	Recipient Address
+	HashAlgorithm HashAlgorithm
+	HashRoot Hash
+	HashCount uint8
+	Timeout uint32
+}
+
+const HtlcCreationDataSize =
+	20 + // Sender
+	20 + // Recipient
+	1 +  // HashAlgorithm
+	32 + // HashRoot
+	1 +  // HashCount
+	4    // Timeout
+
+// Decodes the vesting parameters from the tx data.
+// There are three encodings with increasing length:
+//  - Owner, step blocks
+//    (everything unlocks after step blocks)
+//  - Owner, start, step blocks, step amount
+//  - Owner, start, step blocks, step amount, total amount
+// If omitted, step amount and total amount equal the tx value.
+func (e *ExtendedTx) VestingCreationData() (*VestingCreationData, error) {
+	d := new(VestingCreationData)
+	d.VestingStepAmount = e.Value
+	d.VestingTotalAmount = e.Value
+
+	switch len(e.Data) {
+	case 20 + 4, 20 + 16, 20 + 24:
+		break
+	default:
+		return nil, ErrTx_InvalidCreationData
+	}
+
+	sl := bu.ReadBytes(e.Data)
+	sl.CopyNext(d.Owner[:])
+
+	if len(e.Data) == 20 + 4 {
+		d.VestingStepBlocks = sl.Uint32()
+		return d, nil
+	}
+
+	d.VestingStart = sl.Uint32()
+	d.VestingStepBlocks = sl.Uint32()
+	d.VestingStepAmount = Satoshi(sl.Uint64())
+
+	if len(e.Data) == 20 + 24 {
+		d.VestingTotalAmount = Satoshi(sl.Uint64())
+	}
+
+	return d, nil
+}
+
+// Decodes the HTLC parameters from the tx data
+func (e *ExtendedTx) HtlcCreationData() (*HtlcCreationData, error) {
+	if len(e.Data) != HtlcCreationDataSize {
+		return nil, ErrTx_InvalidCreationData
+	}
+
+	d := new(HtlcCreationData)
+	sl := bu.ReadBytes(e.Data)
+
+	sl.CopyNext(d.Sender[:])
+	sl.CopyNext(d.Recipient[:])
+	d.HashAlgorithm = HashAlgorithm(sl.Uint8())
+	sl.CopyNext(d.HashRoot[:])
+	d.HashCount = sl.Uint8()
+	d.Timeout = sl.Uint32()
+
+	if !d.HashAlgorithm.IsValid() || d.HashCount == 0 {
+		return nil, ErrTx_InvalidCreationData
+	}
+
+	return d, nil
+}
+
+// Decodes the creation data depending on RecipientType.
+// Returns *VestingCreationData or *HtlcCreationData.
+func (e *ExtendedTx) ContractCreationData() (interface{}, error) {
+	if !e.HasFlag(TxFlagContractCreation) {
+		return nil, ErrTx_InvalidContractCreation
+	}
+
+	switch e.RecipientType {
+	case VestingAccount:
+		return e.VestingCreationData()
+	case HtlcAccount:
+		return e.HtlcCreationData()
+	default:
+		return nil, ErrTx_InvalidContractCreation
+	}
+}
+
+// Address of the contract created by this tx:
+// Hash of the tx content with an empty recipient
+func (e *ExtendedTx) ContractCreationAddress() (a Address) {
+	tx := *e
+	tx.Recipient = Address{}
+
+	hash := tx.Hash()
+	copy(a[:], hash[:20])
+	return
+}
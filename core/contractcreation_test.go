@@ -0,0 +1,168 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+)
+
+func newTestVestingCreationTx(data []byte) *ExtendedTx {
+	tx := &ExtendedTx{
+		Data: data,
+		Sender: testPublicKey.ToAddress(),
+		SenderType: BasicAccount,
+		RecipientType: VestingAccount,
+		Value: 1000,
+		ValidityStartHeight: 1,
+		NetworkId: 42,
+		Flags: TxFlagContractCreation,
+	}
+	tx.Recipient = tx.ContractCreationAddress()
+	tx.Proof = testSignatureProof(tx.SerializeContent()).Bytes()
+	return tx
+}
+
+func TestExtendedTx_ContractCreationAddress(t *testing.T) {
+	owner := bytes.Repeat([]byte{0x01}, 20)
+	tx := newTestVestingCreationTx(append(owner, 0x00, 0x00, 0x00, 0x0a))
+
+	// Blake2b of the content with an empty recipient, truncated
+	correctAddr := Address{
+		0x60, 0xde, 0xdb, 0x28, 0x59, 0x0f, 0xe8, 0x1a, 0x42, 0x2d,
+		0x74, 0x5a, 0x11, 0x8f, 0x8d, 0x6c, 0xfb, 0x1b, 0x70, 0xfc,
+	}
+
+	if tx.Recipient != correctAddr {
+		t.Fatalf("Invalid contract address.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			correctAddr, tx.Recipient,
+		)
+	}
+
+	if err := tx.Verify(42); err != nil {
+		t.Fatalf("Valid contract creation rejected: %s", err)
+	}
+
+	tx.Recipient[0]++
+	if err := tx.Verify(42); err != ErrTx_InvalidContractCreation {
+		t.Fatal("Contract creation to wrong address accepted.")
+	}
+
+	tx = newTestVestingCreationTx(append(owner, 0x00, 0x00, 0x00, 0x0a))
+	tx.Flags = 0
+	if err := tx.Verify(42); err != ErrTx_InvalidContractCreation {
+		t.Fatal("Tx to contract without creation flag accepted.")
+	}
+
+	// Basic accounts can't be created
+	tx = newTestVestingCreationTx(nil)
+	tx.RecipientType = BasicAccount
+	if err := tx.Verify(42); err != ErrTx_InvalidContractCreation {
+		t.Fatal("Contract creation of basic account accepted.")
+	}
+}
+
+func TestExtendedTx_VestingCreationData(t *testing.T) {
+	owner := bytes.Repeat([]byte{0x01}, 20)
+
+	// Owner, step blocks
+	tx := newTestVestingCreationTx(append(owner,
+		0x00, 0x00, 0x00, 0x0a))
+	d, err := tx.VestingCreationData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.VestingStart != 0 || d.VestingStepBlocks != 10 ||
+		d.VestingStepAmount != 1000 || d.VestingTotalAmount != 1000 {
+		t.Fatalf("Failed decoding short vesting data: %+v", d)
+	}
+
+	// Owner, start, step blocks, step amount
+	tx = newTestVestingCreationTx(append(owner,
+		0x00, 0x00, 0x00, 0x05,
+		0x00, 0x00, 0x00, 0x0a,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64))
+	d, err = tx.VestingCreationData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.VestingStart != 5 || d.VestingStepBlocks != 10 ||
+		d.VestingStepAmount != 100 || d.VestingTotalAmount != 1000 {
+		t.Fatalf("Failed decoding medium vesting data: %+v", d)
+	}
+
+	// Owner, start, step blocks, step amount, total amount
+	tx = newTestVestingCreationTx(append(owner,
+		0x00, 0x00, 0x00, 0x05,
+		0x00, 0x00, 0x00, 0x0a,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0xf4))
+	d, err = tx.VestingCreationData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.VestingStart != 5 || d.VestingStepBlocks != 10 ||
+		d.VestingStepAmount != 100 || d.VestingTotalAmount != 500 {
+		t.Fatalf("Failed decoding long vesting data: %+v", d)
+	}
+
+	if !bytes.Equal(d.Owner[:], owner) {
+		t.Fatal("Failed decoding vesting owner.")
+	}
+
+	tx = newTestVestingCreationTx(owner)
+	if _, err = tx.VestingCreationData(); err != ErrTx_InvalidCreationData {
+		t.Fatal("Invalid vesting data length accepted.")
+	}
+	if err = tx.Verify(42); err != ErrTx_InvalidCreationData {
+		t.Fatal("Tx with invalid vesting data accepted.")
+	}
+}
+
+func TestExtendedTx_HtlcCreationData(t *testing.T) {
+	data := make([]byte, HtlcCreationDataSize)
+	data[0] = 0x01 // Sender
+	data[20] = 0x02 // Recipient
+	data[40] = uint8(HashAlgorithmSha256)
+	data[41] = 0x03 // Hash root
+	data[73] = 2 // Hash count
+	data[77] = 100 // Timeout
+
+	tx := ExtendedTx{
+		Data: data,
+		RecipientType: HtlcAccount,
+		Flags: TxFlagContractCreation,
+	}
+
+	di, err := tx.ContractCreationData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, ok := di.(*HtlcCreationData)
+	if !ok {
+		t.Fatalf("Invalid creation data type: %T", di)
+	}
+
+	if d.Sender != (Address{0x01}) || d.Recipient != (Address{0x02}) ||
+		d.HashAlgorithm != HashAlgorithmSha256 || d.HashRoot != (Hash{0x03}) ||
+		d.HashCount != 2 || d.Timeout != 100 {
+		t.Fatalf("Failed decoding htlc data: %+v", d)
+	}
+
+	data[40] = 0x07
+	if _, err = tx.HtlcCreationData(); err != ErrTx_InvalidCreationData {
+		t.Fatal("Invalid hash algorithm accepted.")
+	}
+
+	data[40] = uint8(HashAlgorithmSha256)
+	data[73] = 0
+	if _, err = tx.HtlcCreationData(); err != ErrTx_InvalidCreationData {
+		t.Fatal("Zero hash count accepted.")
+	}
+
+	tx.Data = data[1:]
+	if _, err = tx.HtlcCreationData(); err != ErrTx_InvalidCreationData {
+		t.Fatal("Invalid htlc data length accepted.")
+	}
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"math/big"
+	"github.com/terorie/go-nimiq/policy"
+)
+
+// Access to the headers of a chain
+type HeaderSource interface {
+	// Header of the block at height on the chain
+	HeaderAt(height uint32) (*BlockHeader, error)
+}
+
+// Computes the target of the block after head:
+// The average target over the difficulty block window,
+// scaled by how much slower/faster the window was mined
+// than the block time (clamped to the max adjustment).
+func NextTarget(headers HeaderSource, head *BlockHeader) (*big.Int, error) {
+	tailHeight := uint32(1)
+	if head.Height > policy.DifficultyBlockWindow {
+		tailHeight = head.Height - policy.DifficultyBlockWindow
+	}
+
+	tail, err := headers.HeaderAt(tailHeight)
+	if err != nil { return nil, err }
+
+	// Sum of the difficulties in (tail, head]
+	deltaTotalDifficulty := new(big.Float).SetPrec(difficultyPrec)
+	for height := tailHeight + 1; height < head.Height; height++ {
+		header, err := headers.HeaderAt(height)
+		if err != nil { return nil, err }
+		deltaTotalDifficulty.Add(deltaTotalDifficulty, header.Difficulty())
+	}
+	if head.Height > tailHeight {
+		deltaTotalDifficulty.Add(deltaTotalDifficulty, head.Difficulty())
+	}
+
+	return nextTarget(head, tail, deltaTotalDifficulty), nil
+}
+
+func nextTarget(head, tail *BlockHeader, deltaTotalDifficulty *big.Float) *big.Int {
+	// Time it took to mine the window
+	actualTime := float64(int64(head.Timestamp) - int64(tail.Timestamp))
+
+	// Before the window is full, pretend that blocks
+	// with difficulty 1 were mined at the block time
+	// before the genesis block
+	if head.Height <= policy.DifficultyBlockWindow {
+		missing := float64(policy.DifficultyBlockWindow - head.Height + 1)
+		actualTime += missing * policy.BlockTime
+		deltaTotalDifficulty = new(big.Float).Add(deltaTotalDifficulty, big.NewFloat(missing))
+	}
+
+	// Clamp adjustment factor to [1/max, max]
+	expectedTime := float64(policy.DifficultyBlockWindow * policy.BlockTime)
+	adjustment := actualTime / expectedTime
+	if adjustment < 1.0 / policy.DifficultyMaxAdjustmentFactor {
+		adjustment = 1.0 / policy.DifficultyMaxAdjustmentFactor
+	}
+	if adjustment > policy.DifficultyMaxAdjustmentFactor {
+		adjustment = policy.DifficultyMaxAdjustmentFactor
+	}
+
+	averageDifficulty := new(big.Float).SetPrec(difficultyPrec).
+		Quo(deltaTotalDifficulty, big.NewFloat(policy.DifficultyBlockWindow))
+	averageTarget := new(big.Float).SetPrec(difficultyPrec).SetInt(policy.BlockTargetMax)
+	averageTarget.Quo(averageTarget, averageDifficulty)
+
+	// Round to nearest, the division is inexact
+	nextFloat := averageTarget.Mul(averageTarget, big.NewFloat(adjustment))
+	nextFloat.Add(nextFloat, big.NewFloat(0.5))
+	next, _ := nextFloat.Int(nil)
+
+	// Target must be in [1, BlockTargetMax]
+	if next.Cmp(policy.BlockTargetMax) > 0 {
+		next.Set(policy.BlockTargetMax)
+	}
+	if next.Sign() <= 0 {
+		next.SetInt64(1)
+	}
+
+	// Reduce to nBits precision
+	return CompactToTarget(TargetToCompact(next))
+}
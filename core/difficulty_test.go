@@ -0,0 +1,80 @@
+package core
+
+import (
+	"testing"
+	"errors"
+	"github.com/terorie/go-nimiq/policy"
+)
+
+// Synthetic chain, headers[i] is at height i+1
+type testHeaderChain []*BlockHeader
+
+func (c testHeaderChain) HeaderAt(height uint32) (*BlockHeader, error) {
+	if height == 0 || int(height) > len(c) {
+		return nil, errors.New("no header at height")
+	}
+	return c[height - 1], nil
+}
+
+// Chain with a constant target and block interval
+func newTestHeaderChain(length int, nBits uint32, interval uint32) testHeaderChain {
+	chain := make(testHeaderChain, length)
+	for i := range chain {
+		chain[i] = &BlockHeader{
+			Version: BlockHeaderVersion,
+			NBits: nBits,
+			Height: uint32(i + 1),
+			Timestamp: 1523727000 + uint32(i) * interval,
+		}
+	}
+	return chain
+}
+
+func TestNextTarget(t *testing.T) {
+	const nBits = 0x1e00ffff
+
+	// On schedule: target stays
+	chain := newTestHeaderChain(300, nBits, policy.BlockTime)
+	target, err := NextTarget(chain, chain[299])
+	if err != nil { t.Fatal(err) }
+	if c := TargetToCompact(target); c != nBits {
+		t.Fatalf("Target changed on schedule: %08x", c)
+	}
+
+	// Half the block time: target halves
+	chain = newTestHeaderChain(300, nBits, policy.BlockTime / 2)
+	target, err = NextTarget(chain, chain[299])
+	if err != nil { t.Fatal(err) }
+	if c := TargetToCompact(target); c != 0x1d7fff80 {
+		t.Fatalf("Invalid target: %08x", c)
+	}
+
+	// Blocks way too slow: clamped to double target
+	chain = newTestHeaderChain(300, nBits, policy.BlockTime * 10)
+	target, err = NextTarget(chain, chain[299])
+	if err != nil { t.Fatal(err) }
+	if c := TargetToCompact(target); c != 0x1e01fffe {
+		t.Fatalf("Adjustment not clamped: %08x", c)
+	}
+}
+
+func TestNextTarget_Genesis(t *testing.T) {
+	// Never above the max target
+	chain := newTestHeaderChain(10, 0x1f010000, policy.BlockTime * 10)
+	target, err := NextTarget(chain, chain[9])
+	if err != nil { t.Fatal(err) }
+	if target.Cmp(policy.BlockTargetMax) != 0 {
+		t.Fatalf("Target above max: %08x", TargetToCompact(target))
+	}
+
+	// Genesis block has no predecessors
+	target, err = NextTarget(chain, chain[0])
+	if err != nil { t.Fatal(err) }
+	if target.Cmp(policy.BlockTargetMax) != 0 {
+		t.Fatalf("Invalid target after genesis: %08x", TargetToCompact(target))
+	}
+
+	if _, err := NextTarget(testHeaderChain{}, chain[9]); err == nil {
+		t.Fatal("Missing headers not reported")
+	}
+}
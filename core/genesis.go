@@ -0,0 +1,95 @@
+package core
+
+import (
+	"io"
+	"bytes"
+	"errors"
+	"sync"
+)
+
+// Chain parameters of a network:
+// The genesis block and the initial state
+type GenesisConfig struct {
+	NetworkId NetworkId
+	Header BlockHeader
+	Interlink BlockInterlink
+	Body BlockBody
+	// Serialized accounts of the genesis state
+	Accounts []byte
+	// Addresses of the initial peers
+	SeedPeers []string
+}
+
+var (
+	ErrUnknownNetwork = errors.New("no genesis config for network")
+	ErrGenesisMismatch = errors.New("genesis block does not match its header")
+)
+
+// Genesis configs by network.
+// No networks are bundled, load them with
+// LoadGenesis and add them with RegisterGenesis.
+var (
+	genesisConfigs = make(map[NetworkId]*GenesisConfig)
+	genesisLock sync.RWMutex
+)
+
+func RegisterGenesis(config *GenesisConfig) {
+	genesisLock.Lock()
+	defer genesisLock.Unlock()
+	genesisConfigs[config.NetworkId] = config
+}
+
+func GetGenesis(networkId NetworkId) (*GenesisConfig, error) {
+	genesisLock.RLock()
+	defer genesisLock.RUnlock()
+	config, ok := genesisConfigs[networkId]
+	if !ok {
+		return nil, ErrUnknownNetwork
+	}
+	return config, nil
+}
+
+// Decodes a genesis config from the serialized genesis block
+// (header, interlink, body flag, body) and accounts.
+// The body is checked against the header.
+func LoadGenesis(networkId NetworkId, block []byte, accounts []byte, seedPeers []string) (*GenesisConfig, error) {
+	g := &GenesisConfig{
+		NetworkId: networkId,
+		Accounts: accounts,
+		SeedPeers: seedPeers,
+	}
+
+	var b Block
+	err := b.Deserialize(bytes.NewReader(block))
+	if err != nil { return nil, err }
+
+	// The genesis block is always a full block
+	if b.Body == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	// The genesis block has no predecessors (empty interlink)
+	// and a zero interlink hash, as it can't commit to its own hash
+	if b.Header.Height != 1 || len(b.Interlink.Hashes) != 0 ||
+		b.Header.InterlinkHash != (Hash{}) ||
+		b.Body.Hash() != b.Header.BodyHash {
+		return nil, ErrGenesisMismatch
+	}
+
+	g.Header = b.Header
+	g.Interlink = b.Interlink
+	g.Body = *b.Body
+
+	return g, nil
+}
+
+// Hash of the genesis block
+func (g *GenesisConfig) Hash() Hash {
+	return g.Header.Hash()
+}
+
+// Checks a tx against the network of this chain
+func (g *GenesisConfig) VerifyTx(tx Tx) error {
+	return tx.Verify(g.NetworkId)
+}
+
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+	"encoding/hex"
+	"encoding/base64"
+)
+
+func newTestGenesisBlock(t *testing.T) (*BlockHeader, []byte) {
+	body := &BlockBody{
+		MinerAddr: Address{0xaa},
+		ExtraData: []byte("genesis"),
+	}
+	var interlink BlockInterlink
+
+	header := newTestBlockHeader()
+	header.PrevHash = Hash{}
+	header.Height = 1
+	// The genesis block can't commit to its own hash
+	header.InterlinkHash = Hash{}
+	header.BodyHash = body.Hash()
+
+	var buf bytes.Buffer
+	if err := header.Serialize(&buf); err != nil { t.Fatal(err) }
+	if err := interlink.Serialize(&buf); err != nil { t.Fatal(err) }
+	buf.WriteByte(1)
+	if err := body.Serialize(&buf); err != nil { t.Fatal(err) }
+
+	return header, buf.Bytes()
+}
+
+func TestLoadGenesis(t *testing.T) {
+	header, block := newTestGenesisBlock(t)
+
+	g, err := LoadGenesis(NetworkDev, block, nil, []string{"localhost:8443"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Hash() != header.Hash() {
+		t.Fatal("Genesis hash mismatch")
+	}
+	if string(g.Body.ExtraData) != "genesis" {
+		t.Fatal("Invalid body")
+	}
+
+	// Body doesn't match header
+	block[len(block) - 5] ^= 0xff
+	if _, err := LoadGenesis(NetworkDev, block, nil, nil); err != ErrGenesisMismatch {
+		t.Fatal("Modified genesis block accepted")
+	}
+}
+
+func TestGetGenesis(t *testing.T) {
+	_, block := newTestGenesisBlock(t)
+	g, err := LoadGenesis(NetworkBounty, block, nil, nil)
+	if err != nil { t.Fatal(err) }
+
+	RegisterGenesis(g)
+	defer func() {
+		genesisLock.Lock()
+		delete(genesisConfigs, NetworkBounty)
+		genesisLock.Unlock()
+	}()
+
+	if g2, err := GetGenesis(NetworkBounty); err != nil || g2 != g {
+		t.Fatal("Registered genesis not found")
+	}
+	if _, err := GetGenesis(NetworkId(0)); err != ErrUnknownNetwork {
+		t.Fatal("Genesis of unknown network found")
+	}
+
+	tx := &BasicTx{ Recipient: Address{0x01}, Value: 1, NetworkId: NetworkMain }
+	tx.Sign(&testPrivateKey)
+	if err := g.VerifyTx(tx); err != ErrTx_WrongNetwork {
+		t.Fatal("Tx of other network accepted")
+	}
+}
+
+const mainGenesisHash = "264aaf8a4f9828a76c550635da078eb466306a189fcc03710bee9f649c869d12"
+
+// Serialized genesis block of the main network
+const mainGenesisBlock = "AAEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAfNqaf98GZVkFrl29nFNUUUcbB4+m898OKH5bD7R6Vzof79RPH6lxhf2iHpV1Rcl9x2Q/p+Tv3YbgqkJE0eC8XB8BAAAAAAABWtI6mAACGdkAAQAAAAAAAAAAAAAAAAAAAAAAAAAAg2xvdmUgYWkgYW1vciBtb2hhYmJhdCBodWJ1biBjaW50YSBseXVib3YgYmhhbGFiYXNhIGFtb3VyIGthdW5hIHBpJ2FyYSBsaWViZSBlc2hxIHVwZW5kbyBwcmVtYSBhbW9yZSBrYXRyZXNuYW4gc2FyYW5nIGFucHUgcHJlbWEgeWV1AAAAAA=="
+
+// Loads the main network genesis block
+// (without accounts and seed peers)
+func loadMainGenesis(t *testing.T) *GenesisConfig {
+	block, err := base64.StdEncoding.DecodeString(mainGenesisBlock)
+	if err != nil { t.Fatal(err) }
+
+	g, err := LoadGenesis(NetworkMain, block, nil, nil)
+	if err != nil { t.Fatal(err) }
+	return g
+}
+
+func TestLoadGenesis_Main(t *testing.T) {
+	g := loadMainGenesis(t)
+
+	hash := g.Hash()
+	if hex.EncodeToString(hash[:]) != mainGenesisHash {
+		t.Fatalf("Invalid main genesis hash: %x", hash)
+	}
+	if !g.Header.VerifyProofOfWork() {
+		t.Fatal("Invalid main genesis PoW")
+	}
+
+	// Interlink hash (after version and prev hash) must be zero
+	block, _ := base64.StdEncoding.DecodeString(mainGenesisBlock)
+	block[2 + 32] = 0x01
+	if _, err := LoadGenesis(NetworkMain, block, nil, nil); err != ErrGenesisMismatch {
+		t.Fatal("Genesis with interlink hash accepted")
+	}
+}
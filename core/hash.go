@@ -0,0 +1,61 @@
+package core
+
+import (
+	"crypto/sha256"
+	"golang.org/x/crypto/blake2b"
+	"github.com/terorie/go-nimiq/argon2d"
+)
+
+type Hash [32]byte
+
+// Nimiq's "light" hash: Blake2b with a 32 byte digest
+func Blake2bHash(data []byte) Hash {
+	return Hash(blake2b.Sum256(data))
+}
+
+// Nimiq's "hard" hash: Argon2d with fixed parameters
+// (1 pass, 512 KiB memory, 1 lane, 32 byte digest)
+func Argon2dHash(data []byte) (h Hash) {
+	copy(h[:], argon2d.Key(data, []byte("nimiqrocks!"), 1, 512, 1, 32))
+	return
+}
+
+func Sha256Hash(data []byte) Hash {
+	return Hash(sha256.Sum256(data))
+}
+
+// Hash algorithm enum
+type HashAlgorithm uint8
+const (
+	HashAlgorithmBlake2b = HashAlgorithm(1)
+	HashAlgorithmArgon2d = HashAlgorithm(2)
+	HashAlgorithmSha256  = HashAlgorithm(3)
+)
+
+func (h HashAlgorithm) IsValid() bool {
+	switch h {
+	case HashAlgorithmBlake2b, HashAlgorithmArgon2d, HashAlgorithmSha256:
+		return true
+	default:
+		return false
+	}
+}
+
+// Panics if the algorithm is invalid
+func (h HashAlgorithm) Compute(data []byte) Hash {
+	switch h {
+	case HashAlgorithmBlake2b: return Blake2bHash(data)
+	case HashAlgorithmArgon2d: return Argon2dHash(data)
+	case HashAlgorithmSha256: return Sha256Hash(data)
+	default: panic("invalid hash algorithm")
+	}
+}
+
+func (h HashAlgorithm) String() string {
+	switch h {
+	case HashAlgorithmBlake2b: return "Blake2b"
+	case HashAlgorithmArgon2d: return "Argon2d"
+	case HashAlgorithmSha256: return "SHA-256"
+	default: return "Invalid hash algorithm"
+	}
+}
@@ -0,0 +1,374 @@
+package core
+
+import (
+	"io"
+	"bytes"
+	"errors"
+	"math"
+	"encoding/binary"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+)
+
+// Hash time-locked contract:
+// Recipient can withdraw the funds by revealing the
+// pre-image of HashRoot (hashed HashCount times)
+// until Timeout, afterwards Sender can take them back.
+// Partial pre-images (fewer hashing steps) unlock
+// a part of TotalAmount.
+type HtlcContract struct {
+	Balance Satoshi
+	Sender Address
+	Recipient Address
+	HashAlgorithm HashAlgorithm
+	HashRoot Hash
+	HashCount uint8
+	Timeout uint32
+	TotalAmount Satoshi
+}
+
+// Serialized size without the account type
+const HtlcContractSize =
+	8 +  // Balance
+	20 + // Sender
+	20 + // Recipient
+	1 +  // HashAlgorithm
+	32 + // HashRoot
+	1 +  // HashCount
+	4 +  // Timeout
+	8    // TotalAmount
+
+func (_ *HtlcContract) Type() AccountType {
+	return HtlcAccount
+}
+
+func (h *HtlcContract) GetBalance() Satoshi {
+	return h.Balance
+}
+
+func (_ *HtlcContract) SerializedSize() int {
+	return 1 + HtlcContractSize
+}
+
+func (_ *HtlcContract) IsInitial() bool {
+	return false
+}
+
+func (h *HtlcContract) Deserialize(buf *[HtlcContractSize]byte) {
+	sl := bu.ReadBytes(buf[:])
+
+	h.Balance = Satoshi(sl.Uint64())
+	sl.CopyNext(h.Sender[:])
+	sl.CopyNext(h.Recipient[:])
+	h.HashAlgorithm = HashAlgorithm(sl.Uint8())
+	sl.CopyNext(h.HashRoot[:])
+	h.HashCount = sl.Uint8()
+	h.Timeout = sl.Uint32()
+	h.TotalAmount = Satoshi(sl.Uint64())
+}
+
+func (h *HtlcContract) Serialize(buf *[HtlcContractSize]byte) {
+	sl := bu.WriteBytes(buf[:])
+
+	sl.Uint64(uint64(h.Balance))
+	sl.WriteNext(h.Sender[:])
+	sl.WriteNext(h.Recipient[:])
+	sl.Uint8(uint8(h.HashAlgorithm))
+	sl.WriteNext(h.HashRoot[:])
+	sl.Uint8(h.HashCount)
+	sl.Uint32(h.Timeout)
+	sl.Uint64(uint64(h.TotalAmount))
+}
+
+// Checks if the contract allows the outgoing tx
+// at the given block height
+func (h *HtlcContract) VerifyOutgoingTx(tx *ExtendedTx, height uint32) error {
+	minCap, err := h.verifyOutgoingProof(tx, height)
+	if err != nil { return err }
+
+	amount := tx.Value + tx.Fee
+	if amount < tx.Value || amount > h.Balance {
+		return ErrAccount_InsufficientFunds
+	}
+
+	if h.Balance - amount < minCap {
+		return ErrAccount_FundsLocked
+	}
+
+	return nil
+}
+
+// Checks the proof of the outgoing tx against the contract
+// conditions, returns the amount that stays locked
+func (h *HtlcContract) verifyOutgoingProof(tx *ExtendedTx, height uint32) (Satoshi, error) {
+	proof, err := ParseHtlcProof(tx.Proof)
+	if err != nil { return 0, ErrAccount_InvalidProof }
+
+	var minCap Satoshi
+
+	switch proof.Type {
+	case HtlcRegularTransfer:
+		if h.Timeout < height {
+			return 0, ErrAccount_HtlcExpired
+		}
+
+		if proof.HashAlgorithm != h.HashAlgorithm || proof.HashRoot != h.HashRoot {
+			return 0, ErrAccount_InvalidProof
+		}
+
+		if !proof.RecipientSignature.IsSignedBy(&h.Recipient) {
+			return 0, ErrAccount_InvalidProof
+		}
+
+		minCap = h.minCap(proof.HashDepth)
+
+	case HtlcEarlyResolve:
+		if !proof.RecipientSignature.IsSignedBy(&h.Recipient) ||
+			!proof.SenderSignature.IsSignedBy(&h.Sender) {
+			return 0, ErrAccount_InvalidProof
+		}
+
+	case HtlcTimeoutResolve:
+		if h.Timeout >= height {
+			return 0, ErrAccount_HtlcNotExpired
+		}
+
+		if !proof.SenderSignature.IsSignedBy(&h.Sender) {
+			return 0, ErrAccount_InvalidProof
+		}
+	}
+
+	return minCap, nil
+}
+
+// Amount that stays locked when revealing a
+// pre-image that was hashed hashDepth times.
+// Mirrors the float math of the reference client.
+func (h *HtlcContract) minCap(hashDepth uint8) Satoshi {
+	minCap := math.Floor((1 - float64(hashDepth) / float64(h.HashCount)) * float64(h.TotalAmount))
+	// Also catches NaN
+	if !(minCap > 0) {
+		return 0
+	}
+	return Satoshi(minCap)
+}
+
+// HTLC proof type enum
+type HtlcProofType uint8
+const (
+	HtlcRegularTransfer = HtlcProofType(1)
+	HtlcEarlyResolve    = HtlcProofType(2)
+	HtlcTimeoutResolve  = HtlcProofType(3)
+)
+
+func (h HtlcProofType) String() string {
+	switch h {
+	case HtlcRegularTransfer: return "Regular transfer"
+	case HtlcEarlyResolve: return "Early resolve"
+	case HtlcTimeoutResolve: return "Timeout resolve"
+	default: return "Invalid HTLC proof type"
+	}
+}
+
+// Proof of an outgoing tx of a HTLC.
+// Depending on the type, only some fields are set:
+//  - Regular transfer (recipient redeems with pre-image):
+//    HashAlgorithm, HashDepth, HashRoot, PreImage, RecipientSignature
+//  - Early resolve (both parties agree):
+//    RecipientSignature, SenderSignature
+//  - Timeout resolve (sender refunds after timeout):
+//    SenderSignature
+type HtlcProof struct {
+	Type HtlcProofType
+	HashAlgorithm HashAlgorithm
+	HashDepth uint8
+	HashRoot Hash
+	PreImage Hash
+	RecipientSignature *SignatureProof
+	SenderSignature *SignatureProof
+}
+
+var ErrInvalidHtlcProofType = errors.New("invalid htlc proof type")
+var ErrInvalidHashAlgorithm = errors.New("invalid hash algorithm")
+
+// Decodes a proof that spans the whole buffer
+func ParseHtlcProof(buf []byte) (*HtlcProof, error) {
+	r := bytes.NewReader(buf)
+
+	p := new(HtlcProof)
+	err := p.Deserialize(r)
+	if err != nil { return nil, err }
+
+	if r.Len() != 0 {
+		return nil, ErrTrailingProofBytes
+	}
+
+	return p, nil
+}
+
+func (p *HtlcProof) Deserialize(r io.Reader) error {
+	err := binary.Read(r, binary.BigEndian, &p.Type)
+	if err != nil { return err }
+
+	switch p.Type {
+	case HtlcRegularTransfer:
+		var header [2]byte
+		_, err = io.ReadFull(r, header[:])
+		if err != nil { return err }
+
+		p.HashAlgorithm = HashAlgorithm(header[0])
+		p.HashDepth = header[1]
+		if !p.HashAlgorithm.IsValid() {
+			return ErrInvalidHashAlgorithm
+		}
+
+		_, err = io.ReadFull(r, p.HashRoot[:])
+		if err != nil { return err }
+
+		_, err = io.ReadFull(r, p.PreImage[:])
+		if err != nil { return err }
+
+		p.RecipientSignature = new(SignatureProof)
+		return p.RecipientSignature.Deserialize(r)
+
+	case HtlcEarlyResolve:
+		p.RecipientSignature = new(SignatureProof)
+		err = p.RecipientSignature.Deserialize(r)
+		if err != nil { return err }
+
+		p.SenderSignature = new(SignatureProof)
+		return p.SenderSignature.Deserialize(r)
+
+	case HtlcTimeoutResolve:
+		p.SenderSignature = new(SignatureProof)
+		return p.SenderSignature.Deserialize(r)
+
+	default:
+		return ErrInvalidHtlcProofType
+	}
+}
+
+func (p *HtlcProof) Serialize(w io.Writer) error {
+	_, err := w.Write([]byte{uint8(p.Type)})
+	if err != nil { return err }
+
+	switch p.Type {
+	case HtlcRegularTransfer:
+		_, err = w.Write([]byte{uint8(p.HashAlgorithm), p.HashDepth})
+		if err != nil { return err }
+
+		_, err = w.Write(p.HashRoot[:])
+		if err != nil { return err }
+
+		_, err = w.Write(p.PreImage[:])
+		if err != nil { return err }
+
+		return p.RecipientSignature.Serialize(w)
+
+	case HtlcEarlyResolve:
+		err = p.RecipientSignature.Serialize(w)
+		if err != nil { return err }
+
+		return p.SenderSignature.Serialize(w)
+
+	case HtlcTimeoutResolve:
+		return p.SenderSignature.Serialize(w)
+
+	default:
+		return ErrInvalidHtlcProofType
+	}
+}
+
+func (p *HtlcProof) Bytes() []byte {
+	return bu.Serialize(0, p.Serialize)
+}
+
+// Checks the pre-image and signatures of the proof
+// without knowledge of the contract
+func (p *HtlcProof) Verify(payload []byte) error {
+	switch p.Type {
+	case HtlcRegularTransfer:
+		// Hashing the pre-image HashDepth times
+		// must result in the provided HashRoot
+		hash := p.PreImage
+		for i := uint8(0); i < p.HashDepth; i++ {
+			hash = p.HashAlgorithm.Compute(hash[:])
+		}
+
+		if hash != p.HashRoot {
+			return ErrTx_InvalidProof
+		}
+
+		if !p.RecipientSignature.Verify(nil, payload) {
+			return ErrTx_InvalidSignature
+		}
+
+	case HtlcEarlyResolve:
+		if !p.RecipientSignature.Verify(nil, payload) ||
+			!p.SenderSignature.Verify(nil, payload) {
+			return ErrTx_InvalidSignature
+		}
+
+	case HtlcTimeoutResolve:
+		if !p.SenderSignature.Verify(nil, payload) {
+			return ErrTx_InvalidSignature
+		}
+
+	default:
+		return ErrTx_InvalidProof
+	}
+
+	return nil
+}
+
+func (h *HtlcContract) WithOutgoingTx(tx Tx, height uint32, txCache TxCache) (Account, error) {
+	e := tx.ToExtended()
+	if e.SenderType != HtlcAccount {
+		return nil, ErrAccount_TypeMismatch
+	}
+
+	err := h.VerifyOutgoingTx(e, height)
+	if err != nil { return nil, err }
+
+	balance, err := outgoingBalance(h.Balance, e, height, txCache)
+	if err != nil { return nil, err }
+
+	newH := *h
+	newH.Balance = balance
+	return &newH, nil
+}
+
+// Undoes the outgoing tx. The proof and contract
+// conditions are checked like for WithOutgoingTx,
+// only the balance checks are skipped.
+func (h *HtlcContract) RevertOutgoingTx(tx Tx, height uint32) (Account, error) {
+	e := tx.ToExtended()
+	if e.SenderType != HtlcAccount {
+		return nil, ErrAccount_TypeMismatch
+	}
+
+	_, err := h.verifyOutgoingProof(e, height)
+	if err != nil { return nil, err }
+
+	balance, err := revertOutgoingBalance(h.Balance, e)
+	if err != nil { return nil, err }
+
+	newH := *h
+	newH.Balance = balance
+	return &newH, nil
+}
+
+func (h *HtlcContract) WithIncomingTx(tx Tx, height uint32) (Account, error) {
+	return contractIncomingTx()
+}
+
+func (h *HtlcContract) RevertIncomingTx(tx Tx, height uint32) (Account, error) {
+	return contractIncomingTx()
+}
+
+func (h *HtlcContract) WithContractCommand(tx Tx, height uint32) (Account, error) {
+	return contractWithContractCommand(h, tx)
+}
+
+func (h *HtlcContract) RevertContractCommand(tx Tx, height uint32) (Account, error) {
+	return contractRevertContractCommand(h, tx)
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+)
+
+func TestHtlcContract_Serialize(t *testing.T) {
+	h := HtlcContract{
+		Balance: 1000,
+		Sender: Address{0x01},
+		Recipient: Address{0x02},
+		HashAlgorithm: HashAlgorithmSha256,
+		HashRoot: Hash{0x03},
+		HashCount: 4,
+		Timeout: 0x05060708,
+		TotalAmount: 1000,
+	}
+
+	var buf [HtlcContractSize]byte
+	h.Serialize(&buf)
+
+	// Hash algorithm and root follow sender and recipient
+	if buf[48] != uint8(HashAlgorithmSha256) || buf[49] != 0x03 || buf[81] != 4 {
+		t.Fatalf("Failed serializing htlc: hex(%x)", buf)
+	}
+
+	var h2 HtlcContract
+	h2.Deserialize(&buf)
+
+	if h2 != h {
+		t.Fatalf("Failed deserializing htlc: %+v", h2)
+	}
+}
+
+func TestHtlcProof_Serialize(t *testing.T) {
+	sig := testSignatureProof([]byte{0x00})
+
+	proofs := []HtlcProof{
+		{
+			Type: HtlcRegularTransfer,
+			HashAlgorithm: HashAlgorithmBlake2b,
+			HashDepth: 2,
+			HashRoot: Hash{0x01},
+			PreImage: Hash{0x02},
+			RecipientSignature: sig,
+		},
+		{ Type: HtlcEarlyResolve, RecipientSignature: sig, SenderSignature: sig },
+		{ Type: HtlcTimeoutResolve, SenderSignature: sig },
+	}
+
+	sizes := []int{
+		1 + 2 + 32 + 32 + sig.SerializedSize(),
+		1 + 2 * sig.SerializedSize(),
+		1 + sig.SerializedSize(),
+	}
+
+	for i, proof := range proofs {
+		buf := proof.Bytes()
+		if len(buf) != sizes[i] {
+			t.Fatalf("Invalid size of %s proof: %d", proof.Type, len(buf))
+		}
+
+		parsed, err := ParseHtlcProof(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(parsed.Bytes(), buf) {
+			t.Fatalf("Failed parsing %s proof.", proof.Type)
+		}
+
+		if _, err = ParseHtlcProof(append(buf, 0x00)); err != ErrTrailingProofBytes {
+			t.Fatal("Accepted trailing bytes after proof.")
+		}
+	}
+
+	if _, err := ParseHtlcProof([]byte{0x04}); err != ErrInvalidHtlcProofType {
+		t.Fatal("Accepted invalid proof type.")
+	}
+}
+
+func TestHtlcContract_VerifyOutgoingTx(t *testing.T) {
+	for _, algorithm := range []HashAlgorithm{HashAlgorithmBlake2b, HashAlgorithmArgon2d, HashAlgorithmSha256} {
+		preImage := Hash{0x42}
+		hash1 := algorithm.Compute(preImage[:])
+		hash2 := algorithm.Compute(hash1[:])
+
+		h := HtlcContract{
+			Balance: 1000,
+			Sender: Address{0x01},
+			Recipient: testPublicKey.ToAddress(),
+			HashAlgorithm: algorithm,
+			HashRoot: hash2,
+			HashCount: 2,
+			Timeout: 100,
+			TotalAmount: 1000,
+		}
+
+		tx := ExtendedTx{
+			Sender: Address{0x03},
+			SenderType: HtlcAccount,
+			Recipient: testPublicKey.ToAddress(),
+			Value: 990,
+			Fee: 10,
+			NetworkId: 42,
+		}
+
+		// Redeem everything with the full pre-image
+		proof := HtlcProof{
+			Type: HtlcRegularTransfer,
+			HashAlgorithm: algorithm,
+			HashDepth: 2,
+			HashRoot: hash2,
+			PreImage: preImage,
+			RecipientSignature: testSignatureProof(tx.SerializeContent()),
+		}
+		tx.Proof = proof.Bytes()
+
+		if err := tx.Verify(42); err != nil {
+			t.Fatalf("Valid %s htlc tx rejected: %s", algorithm, err)
+		}
+
+		if err := h.VerifyOutgoingTx(&tx, 100); err != nil {
+			t.Fatalf("Valid %s htlc redeem rejected: %s", algorithm, err)
+		}
+
+		if err := h.VerifyOutgoingTx(&tx, 101); err != ErrAccount_HtlcExpired {
+			t.Fatal("Redeem after timeout accepted.")
+		}
+
+		// Partial pre-image: Only half of the funds unlocked
+		proof.HashDepth = 1
+		proof.PreImage = hash1
+		tx.Proof = proof.Bytes()
+
+		if err := tx.Verify(42); err != nil {
+			t.Fatalf("Valid partial htlc tx rejected: %s", err)
+		}
+
+		if err := h.VerifyOutgoingTx(&tx, 50); err != ErrAccount_FundsLocked {
+			t.Fatal("Partial pre-image unlocked all funds.")
+		}
+
+		// Wrong pre-image
+		proof.PreImage = Hash{0x43}
+		tx.Proof = proof.Bytes()
+
+		if err := tx.Verify(42); err != ErrTx_InvalidProof {
+			t.Fatal("Wrong pre-image accepted.")
+		}
+	}
+}
+
+func TestHtlcContract_Resolve(t *testing.T) {
+	testAddr := testPublicKey.ToAddress()
+
+	h := HtlcContract{
+		Balance: 1000,
+		Sender: testAddr,
+		Recipient: Address{0x02},
+		HashAlgorithm: HashAlgorithmBlake2b,
+		HashCount: 1,
+		Timeout: 100,
+		TotalAmount: 1000,
+	}
+
+	tx := ExtendedTx{
+		Sender: Address{0x03},
+		SenderType: HtlcAccount,
+		Recipient: testAddr,
+		Value: 1000,
+		NetworkId: 42,
+	}
+
+	sig := testSignatureProof(tx.SerializeContent())
+
+	// Refund after timeout
+	timeoutProof := HtlcProof{ Type: HtlcTimeoutResolve, SenderSignature: sig }
+	tx.Proof = timeoutProof.Bytes()
+
+	if err := h.VerifyOutgoingTx(&tx, 101); err != nil {
+		t.Fatalf("Valid timeout resolve rejected: %s", err)
+	}
+
+	if err := h.VerifyOutgoingTx(&tx, 100); err != ErrAccount_HtlcNotExpired {
+		t.Fatal("Timeout resolve before timeout accepted.")
+	}
+
+	// Early resolve needs both parties
+	earlyProof := HtlcProof{ Type: HtlcEarlyResolve, RecipientSignature: sig, SenderSignature: sig }
+	tx.Proof = earlyProof.Bytes()
+
+	if err := h.VerifyOutgoingTx(&tx, 50); err != ErrAccount_InvalidProof {
+		t.Fatal("Early resolve without recipient signature accepted.")
+	}
+
+	h.Recipient = testAddr
+	if err := h.VerifyOutgoingTx(&tx, 50); err != nil {
+		t.Fatalf("Valid early resolve rejected: %s", err)
+	}
+}
+
+func TestHtlcContract_RevertOutgoingTx(t *testing.T) {
+	testAddr := testPublicKey.ToAddress()
+
+	// Contract after the refund of everything
+	h := HtlcContract{
+		Sender: testAddr,
+		Recipient: Address{0x02},
+		HashAlgorithm: HashAlgorithmBlake2b,
+		HashCount: 1,
+		Timeout: 100,
+		TotalAmount: 1000,
+	}
+
+	tx := ExtendedTx{
+		Sender: Address{0x03},
+		SenderType: HtlcAccount,
+		Recipient: testAddr,
+		Value: 1000,
+		NetworkId: 42,
+	}
+	timeoutProof := HtlcProof{ Type: HtlcTimeoutResolve, SenderSignature: testSignatureProof(tx.SerializeContent()) }
+	tx.Proof = timeoutProof.Bytes()
+
+	// No balance check: The funds are restored
+	account, err := h.RevertOutgoingTx(&tx, 101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.GetBalance() != 1000 {
+		t.Fatalf("Invalid balance after revert: %d", account.GetBalance())
+	}
+
+	// Contract conditions are checked
+	if _, err := h.RevertOutgoingTx(&tx, 100); err != ErrAccount_HtlcNotExpired {
+		t.Fatal("Timeout resolve reverted before timeout.")
+	}
+
+	tx.Proof = []byte{0x03}
+	if _, err := h.RevertOutgoingTx(&tx, 101); err != ErrAccount_InvalidProof {
+		t.Fatal("Invalid proof reverted.")
+	}
+}
@@ -0,0 +1,31 @@
+package core
+
+// ID of a Nimiq network, signed into every tx
+// to prevent replays on other networks
+type NetworkId uint8
+
+const (
+	NetworkTest   = NetworkId(1)
+	NetworkDev    = NetworkId(2)
+	NetworkBounty = NetworkId(3)
+	NetworkMain   = NetworkId(42)
+)
+
+func (n NetworkId) String() string {
+	switch n {
+	case NetworkTest: return "test"
+	case NetworkDev: return "dev"
+	case NetworkBounty: return "bounty"
+	case NetworkMain: return "main"
+	default: return "unknown"
+	}
+}
+
+func (n NetworkId) IsValid() bool {
+	switch n {
+	case NetworkTest, NetworkDev, NetworkBounty, NetworkMain:
+		return true
+	default:
+		return false
+	}
+}
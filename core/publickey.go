@@ -1,3 +1,11 @@
 package core
 
 type PublicKey [32]byte
+
+// Derives the address of the key pair:
+// The first 20 bytes of the Blake2b hash of the public key
+func (p *PublicKey) ToAddress() (a Address) {
+	hash := Blake2bHash(p[:])
+	copy(a[:], hash[:20])
+	return
+}
@@ -0,0 +1,19 @@
+package core
+
+import "testing"
+
+func TestPublicKey_ToAddress(t *testing.T) {
+	correctAddr := Address{
+		0xb5, 0x24, 0x68, 0x7e, 0xb1, 0xaf, 0x8d, 0x9c, 0x0b, 0x23,
+		0x22, 0xf6, 0x38, 0xfd, 0x33, 0xdf, 0x3b, 0x59, 0xad, 0x1b,
+	}
+
+	addr := testPublicKey.ToAddress()
+	if addr != correctAddr {
+		t.Fatalf("Invalid address derived.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			correctAddr, addr,
+		)
+	}
+}
@@ -1,3 +1,15 @@
 package core
 
+import "github.com/terorie/go-nimiq/ed25519"
+
 type Signature [64]byte
+
+// Checks if the signature over message was
+// created by the owner of publicKey
+func (s *Signature) Verify(publicKey *PublicKey, message []byte) bool {
+	return ed25519.Verify(
+		(*ed25519.Signature)(s),
+		message,
+		(*ed25519.PublicKey)(publicKey),
+	)
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"io"
+	"bytes"
+	"errors"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+	"github.com/terorie/go-nimiq/merkle"
+)
+
+var ErrTrailingProofBytes = errors.New("unexpected bytes after proof")
+
+// Proof of the sender of a tx:
+// A signature plus the path from the signing key
+// to the sender address. For basic accounts the
+// path is empty, for multisig wallets it leads to
+// the Merkle root of all possible signer keys.
+type SignatureProof struct {
+	PublicKey PublicKey
+	MerklePath merkle.Path
+	Signature Signature
+}
+
+// Creates the proof of a single key (basic account)
+func NewSingleSigProof(publicKey *PublicKey, signature *Signature) *SignatureProof {
+	return &SignatureProof{
+		PublicKey: *publicKey,
+		Signature: *signature,
+	}
+}
+
+// Decodes a proof that spans the whole buffer
+// (like ExtendedTx.Proof of a basic account sender)
+func ParseSignatureProof(buf []byte) (*SignatureProof, error) {
+	r := bytes.NewReader(buf)
+
+	p := new(SignatureProof)
+	err := p.Deserialize(r)
+	if err != nil { return nil, err }
+
+	if r.Len() != 0 {
+		return nil, ErrTrailingProofBytes
+	}
+
+	return p, nil
+}
+
+func (p *SignatureProof) SerializedSize() int {
+	return 32 + p.MerklePath.SerializedSize() + 64
+}
+
+func (p *SignatureProof) Serialize(w io.Writer) error {
+	_, err := w.Write(p.PublicKey[:])
+	if err != nil { return err }
+
+	err = p.MerklePath.Serialize(w)
+	if err != nil { return err }
+
+	_, err = w.Write(p.Signature[:])
+	return err
+}
+
+func (p *SignatureProof) Deserialize(r io.Reader) error {
+	_, err := io.ReadFull(r, p.PublicKey[:])
+	if err != nil { return err }
+
+	err = p.MerklePath.Deserialize(r)
+	if err != nil { return err }
+
+	_, err = io.ReadFull(r, p.Signature[:])
+	return err
+}
+
+func (p *SignatureProof) Bytes() []byte {
+	return bu.Serialize(p.SerializedSize(), p.Serialize)
+}
+
+// Address of the signer:
+// The Merkle root with the public key as leaf, truncated
+func (p *SignatureProof) ComputeAddress() (a Address) {
+	root := p.MerklePath.ComputeRoot(merkle.BytesLeaf(p.PublicKey[:]))
+	copy(a[:], root[:20])
+	return
+}
+
+// Checks if the public key belongs to address
+func (p *SignatureProof) IsSignedBy(address *Address) bool {
+	return p.ComputeAddress() == *address
+}
+
+// Checks if the proof is valid for the signed payload.
+// If address is nil, only the signature is checked.
+func (p *SignatureProof) Verify(address *Address, payload []byte) bool {
+	if address != nil && !p.IsSignedBy(address) {
+		return false
+	}
+	return p.Signature.Verify(&p.PublicKey, payload)
+}
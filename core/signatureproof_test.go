@@ -0,0 +1,101 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+	"github.com/terorie/go-nimiq/ed25519"
+	"github.com/terorie/go-nimiq/merkle"
+)
+
+func TestSignatureProof_Verify(t *testing.T) {
+	tx := BasicTx{ Value: 1, NetworkId: 42 }
+	tx.Sign(&testPrivateKey)
+	content := tx.SerializeContent()
+
+	// Single signature (basic account) proof
+	proof := NewSingleSigProof(&tx.SenderPublicKey, &tx.Signature)
+
+	sender := tx.Sender()
+	if !proof.Verify(&sender, content) {
+		t.Fatal("Failed to verify valid proof.")
+	}
+
+	if !proof.Verify(nil, content) {
+		t.Fatal("Failed to verify valid proof without address.")
+	}
+
+	var otherAddr Address
+	if proof.Verify(&otherAddr, content) {
+		t.Fatal("Accepted proof for wrong address.")
+	}
+
+	tx.Fee++
+	if proof.Verify(&sender, tx.SerializeContent()) {
+		t.Fatal("Accepted proof for wrong payload.")
+	}
+}
+
+func TestSignatureProof_Multisig(t *testing.T) {
+	var leftHash, rightHash Hash
+	for i := range leftHash {
+		leftHash[i] = 0x11
+		rightHash[i] = 0x22
+	}
+
+	proof := SignatureProof{
+		PublicKey: testPublicKey,
+		MerklePath: merkle.Path{
+			{ Hash: leftHash, Left: true },
+			{ Hash: rightHash, Left: false },
+		},
+	}
+
+	// Blake2b(Blake2b(leftHash + Blake2b(key)) + rightHash)
+	correctAddr := Address{
+		0xc8, 0x6b, 0x24, 0x4d, 0x84, 0xb6, 0x36, 0x7c, 0xbf, 0xe3,
+		0xb2, 0x00, 0x65, 0x29, 0x48, 0x55, 0xa7, 0x07, 0x7f, 0xe8,
+	}
+
+	if !proof.IsSignedBy(&correctAddr) {
+		t.Fatalf("Invalid multisig address computed: hex(%x)", proof.ComputeAddress())
+	}
+
+	singleAddr := testPublicKey.ToAddress()
+	if proof.IsSignedBy(&singleAddr) {
+		t.Fatal("Merkle path ignored.")
+	}
+
+	// Serialize and parse again
+	buf := proof.Bytes()
+
+	if len(buf) != proof.SerializedSize() || len(buf) != 32 + 1 + 1 + 64 + 64 {
+		t.Fatalf("Invalid serialized size: %d", len(buf))
+	}
+
+	// Count (2), left bits (first node left)
+	if buf[32] != 2 || buf[33] != 0x80 {
+		t.Fatalf("Invalid Merkle path header: hex(%x)", buf[32:34])
+	}
+
+	parsed, err := ParseSignatureProof(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(parsed.Bytes(), buf) || !parsed.IsSignedBy(&correctAddr) {
+		t.Fatal("Failed parsing proof.")
+	}
+
+	// Overlong proofs are rejected
+	_, err = ParseSignatureProof(append(buf, 0x00))
+	if err != ErrTrailingProofBytes {
+		t.Fatal("Accepted trailing bytes after proof.")
+	}
+}
+
+// Creates a single signature proof of payload by testPrivateKey
+func testSignatureProof(payload []byte) *SignatureProof {
+	publicKey := ed25519.PublicKey(testPublicKey)
+	signature := Signature(ed25519.Sign(payload, &publicKey, &testPrivateKey))
+	return NewSingleSigProof(&testPublicKey, &signature)
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"math/big"
+	"github.com/terorie/go-nimiq/policy"
+)
+
+// Precision of difficulty values in bits
+const difficultyPrec = 256
+
+// Converts a compact target (nBits) to the full target:
+// The lowest 3 bytes are the mantissa,
+// the highest byte is the size of the target in bytes.
+func CompactToTarget(compact uint32) *big.Int {
+	size := int(compact >> 24)
+	target := big.NewInt(int64(compact & 0xFFFFFF))
+	if size >= 3 {
+		return target.Lsh(target, uint(8 * (size - 3)))
+	} else {
+		return target.Rsh(target, uint(8 * (3 - size)))
+	}
+}
+
+// Converts a target to the compact form (nBits).
+// Targets with more than 3 significant bytes are truncated.
+func TargetToCompact(target *big.Int) uint32 {
+	// Size in bytes, the mantissa is signed
+	// (as in Bitcoin) so a set top bit needs another byte
+	size := (target.BitLen() + 7) / 8
+	if size == 0 {
+		size = 1
+	}
+	if target.BitLen() % 8 == 0 && target.Sign() > 0 {
+		size++
+	}
+
+	mantissa := new(big.Int)
+	if size >= 3 {
+		mantissa.Rsh(target, uint(8 * (size - 3)))
+	} else {
+		mantissa.Lsh(target, uint(8 * (3 - size)))
+	}
+
+	return uint32(size) << 24 | uint32(mantissa.Uint64() & 0xFFFFFF)
+}
+
+// Checks that the target is within [1, BlockTargetMax]
+func IsValidTarget(target *big.Int) bool {
+	return target.Sign() > 0 && target.Cmp(policy.BlockTargetMax) <= 0
+}
+
+func IsValidCompact(compact uint32) bool {
+	return IsValidTarget(CompactToTarget(compact))
+}
+
+// Difficulty is the inverse of the target
+// relative to the max target (difficulty 1)
+func TargetToDifficulty(target *big.Int) *big.Float {
+	max := new(big.Float).SetPrec(difficultyPrec).SetInt(policy.BlockTargetMax)
+	t := new(big.Float).SetPrec(difficultyPrec).SetInt(target)
+	return max.Quo(max, t)
+}
+
+// Converts a difficulty to the target,
+// rounding down to the next integer
+func DifficultyToTarget(difficulty *big.Float) *big.Int {
+	max := new(big.Float).SetPrec(difficultyPrec).SetInt(policy.BlockTargetMax)
+	target, _ := max.Quo(max, difficulty).Int(nil)
+	return target
+}
+
+func CompactToDifficulty(compact uint32) *big.Float {
+	return TargetToDifficulty(CompactToTarget(compact))
+}
+
+func DifficultyToCompact(difficulty *big.Float) uint32 {
+	return TargetToCompact(DifficultyToTarget(difficulty))
+}
+
+// Interprets the hash as big-endian number
+func HashToTarget(hash Hash) *big.Int {
+	return new(big.Int).SetBytes(hash[:])
+}
+
+// Checks if the PoW hash meets the target
+func IsProofOfWork(powHash Hash, target *big.Int) bool {
+	return HashToTarget(powHash).Cmp(target) <= 0
+}
+
+// Number of bits needed for the target: ceil(log2(target))
+func TargetHeight(target *big.Int) int {
+	height := target.BitLen()
+	// Powers of two need one bit less
+	if height > 0 && target.TrailingZeroBits() == uint(height - 1) {
+		height--
+	}
+	return height
+}
+
+// Number of bits the target is below the max target.
+// The depth of a PoW hash is the superblock level of its block.
+func TargetDepth(target *big.Int) int {
+	return policy.BlockTargetMaxHeight - TargetHeight(target)
+}
+
+func HashDepth(hash Hash) int {
+	return TargetDepth(HashToTarget(hash))
+}
+
+// Difficulty the PoW hash would have met
+func RealDifficulty(powHash Hash) *big.Float {
+	return TargetToDifficulty(HashToTarget(powHash))
+}
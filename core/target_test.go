@@ -0,0 +1,78 @@
+package core
+
+import (
+	"testing"
+	"math/big"
+	"github.com/terorie/go-nimiq/policy"
+)
+
+func TestTargetToCompact(t *testing.T) {
+	if c := TargetToCompact(policy.BlockTargetMax); c != 0x1f010000 {
+		t.Fatalf("Invalid compact of max target: %08x", c)
+	}
+
+	for _, compact := range []uint32{ 0x1f010000, 0x1f7fffff, 0x1e00ffff, 0x1d123456, 0x03123456, 0x02120000 } {
+		if c := TargetToCompact(CompactToTarget(compact)); c != compact {
+			t.Fatalf("Compact %08x changed to %08x", compact, c)
+		}
+	}
+
+	// Set top bit needs another byte
+	if c := TargetToCompact(big.NewInt(0x80)); c != 0x02008000 {
+		t.Fatalf("Invalid compact: %08x", c)
+	}
+
+	// Lower bytes get truncated
+	target := big.NewInt(0x12345678)
+	if c := TargetToCompact(target); c != 0x04123456 {
+		t.Fatalf("Invalid compact: %08x", c)
+	}
+}
+
+func TestIsValidCompact(t *testing.T) {
+	if !IsValidCompact(0x1f010000) {
+		t.Fatal("Max target rejected")
+	}
+	if IsValidCompact(0x1f010001) {
+		t.Fatal("Target above max accepted")
+	}
+	if IsValidCompact(0x01000000) {
+		t.Fatal("Zero target accepted")
+	}
+}
+
+func TestDifficulty(t *testing.T) {
+	if d, _ := CompactToDifficulty(0x1f010000).Float64(); d != 1 {
+		t.Fatalf("Invalid difficulty of max target: %f", d)
+	}
+
+	// Half the target, double the difficulty
+	if d, _ := CompactToDifficulty(0x1e800000).Float64(); d != 2 {
+		t.Fatalf("Invalid difficulty: %f", d)
+	}
+
+	if c := DifficultyToCompact(big.NewFloat(256)); c != 0x1e010000 {
+		t.Fatalf("Invalid compact of difficulty 256: %08x", c)
+	}
+
+	// Exact for large difficulties
+	target := CompactToTarget(0x1b0404cb)
+	if DifficultyToTarget(TargetToDifficulty(target)).Cmp(target) != 0 {
+		t.Fatal("Target changed after conversion")
+	}
+}
+
+func TestTargetDepth(t *testing.T) {
+	if TargetDepth(policy.BlockTargetMax) != 0 {
+		t.Fatal("Invalid depth of max target")
+	}
+	if TargetDepth(CompactToTarget(0x1e010000)) != 8 {
+		t.Fatal("Invalid depth")
+	}
+
+	// Hash with 20 leading zero bits
+	hash := Hash{0x00, 0x00, 0x0f}
+	if HashDepth(hash) != 4 {
+		t.Fatalf("Invalid hash depth: %d", HashDepth(hash))
+	}
+}
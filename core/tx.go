@@ -15,8 +15,23 @@ const (
 type Tx interface {
 	//Deserialize(reader io.Reader)
 	Format() TxFormat
+	// Size of the tx as written by SerializeTx
+	// (including the format byte)
+	SerializedSize() int
+	// Tx content that is signed and hashed
+	// (everything except the signature/proof)
+	SerializeContent() []byte
+	// Blake2b hash of the content (tx ID)
+	Hash() Hash
+	// Static checks (valid signature/proof,
+	// valid values, matching network ID)
+	Verify(networkId NetworkId) error
+	// Equivalent tx in the extended format
+	ToExtended() *ExtendedTx
 }
 
+var ErrUnsupportedTxFormat = errors.New("unsupported tx format")
+
 func DeserializeTx(r io.Reader) (Tx, error) {
 	// Read transaction format (1 byte)
 	var format TxFormat
@@ -39,11 +54,36 @@ func DeserializeTx(r io.Reader) (Tx, error) {
 	case TxFormatExtended:
 		// Deserialize extended transaction
 		extendedTx := new(ExtendedTx)
-		extendedTx.Deserialize(r)
+		err = extendedTx.Deserialize(r)
+		if err != nil { return nil, err }
 
 		return extendedTx, nil
 
 	default:
-		return nil, errors.New("unsupported tx format")
+		return nil, ErrUnsupportedTxFormat
+	}
+}
+
+// Writes the tx format byte followed by the tx.
+// The output can be read back with DeserializeTx.
+func SerializeTx(w io.Writer, tx Tx) error {
+	// Write transaction format (1 byte)
+	_, err := w.Write([]byte{uint8(tx.Format())})
+	if err != nil { return err }
+
+	switch t := tx.(type) {
+	case *BasicTx:
+		// Static length: Fill buffer and write it
+		var txBuf [BasicTxSize]byte
+		t.Serialize(&txBuf)
+
+		_, err = w.Write(txBuf[:])
+		return err
+
+	case *ExtendedTx:
+		return t.Serialize(w)
+
+	default:
+		return ErrUnsupportedTxFormat
 	}
 }
@@ -2,6 +2,7 @@ package core
 
 import (
 	bu "github.com/terorie/go-nimiq/bufferutils"
+	"github.com/terorie/go-nimiq/ed25519"
 )
 
 type BasicTx struct {
@@ -10,7 +11,7 @@ type BasicTx struct {
 	Value Satoshi
 	Fee Satoshi
 	ValidityStartHeight uint32
-	NetworkId uint8
+	NetworkId NetworkId
 	Signature Signature
 }
 
@@ -23,6 +24,18 @@ const BasicTxSize =
 	1 +  // NetworkId
 	64   // Signature
 
+const BasicTxContentSize =
+	2 +  // Data length (always zero)
+	20 + // Sender
+	1 +  // SenderType
+	20 + // Recipient
+	1 +  // RecipientType
+	8 +  // Value
+	8 +  // Fee
+	4 +  // ValidityStartHeight
+	1 +  // NetworkId
+	1    // Flags
+
 func (_ *BasicTx) Format() TxFormat {
 	return TxFormatBasic
 }
@@ -35,18 +48,92 @@ func (t *BasicTx) Deserialize(buf *[BasicTxSize]byte) {
 	t.Value = Satoshi(sl.Uint64())
 	t.Fee = Satoshi(sl.Uint64())
 	t.ValidityStartHeight = sl.Uint32()
-	t.NetworkId = sl.Uint8()
+	t.NetworkId = NetworkId(sl.Uint8())
 	sl.CopyNext(t.Signature[:])
 }
 
-func (t *BasicTx) Serialize(buf [BasicTxSize]byte) {
+func (t *BasicTx) Serialize(buf *[BasicTxSize]byte) {
 	sl := bu.WriteBytes(buf[:])
 
-	sl.Uint8(uint8(TxFormatBasic))
 	sl.WriteNext(t.SenderPublicKey[:])
 	sl.WriteNext(t.Recipient[:])
 	sl.Uint64(uint64(t.Value))
 	sl.Uint64(uint64(t.Fee))
 	sl.Uint32(t.ValidityStartHeight)
-	sl.Uint8(t.NetworkId)
+	sl.Uint8(uint8(t.NetworkId))
+	sl.WriteNext(t.Signature[:])
+}
+
+func (_ *BasicTx) SerializedSize() int {
+	return 1 + BasicTxSize
+}
+
+// Address derived from SenderPublicKey
+func (t *BasicTx) Sender() Address {
+	return t.SenderPublicKey.ToAddress()
+}
+
+// Checks if SenderPublicKey belongs to the given address.
+// Does not check the signature, see VerifySignature.
+func (t *BasicTx) IsSentBy(address *Address) bool {
+	return t.Sender() == *address
+}
+
+// Basic txs are serialized like extended txs
+// without data between two basic accounts
+func (t *BasicTx) SerializeContent() []byte {
+	var buf [BasicTxContentSize]byte
+	sl := bu.WriteBytes(buf[:])
+
+	sender := t.Sender()
+
+	sl.Uint16(0)
+	sl.WriteNext(sender[:])
+	sl.Uint8(uint8(BasicAccount))
+	sl.WriteNext(t.Recipient[:])
+	sl.Uint8(uint8(BasicAccount))
+	sl.Uint64(uint64(t.Value))
+	sl.Uint64(uint64(t.Fee))
+	sl.Uint32(t.ValidityStartHeight)
+	sl.Uint8(uint8(t.NetworkId))
+	sl.Uint8(0)
+
+	return buf[:]
+}
+
+func (t *BasicTx) Hash() Hash {
+	return Blake2bHash(t.SerializeContent())
+}
+
+// Sets SenderPublicKey to the key pair of privateKey
+// and signs the tx content with it
+func (t *BasicTx) Sign(privateKey *ed25519.PrivateKey) {
+	publicKey := ed25519.PublicKeyDerive(privateKey)
+	t.SenderPublicKey = PublicKey(publicKey)
+
+	signature := ed25519.Sign(t.SerializeContent(), &publicKey, privateKey)
+	t.Signature = Signature(signature)
+}
+
+// Checks if Signature is a valid signature
+// of the tx content by SenderPublicKey
+func (t *BasicTx) VerifySignature() bool {
+	return t.Signature.Verify(&t.SenderPublicKey, t.SerializeContent())
+}
+
+// Extended tx with the same content and
+// a single signature proof
+func (t *BasicTx) ToExtended() *ExtendedTx {
+	return &ExtendedTx{
+		Data: []byte{},
+		Sender: t.Sender(),
+		SenderType: BasicAccount,
+		Recipient: t.Recipient,
+		RecipientType: BasicAccount,
+		Value: t.Value,
+		Fee: t.Fee,
+		ValidityStartHeight: t.ValidityStartHeight,
+		NetworkId: t.NetworkId,
+		Proof: NewSingleSigProof(&t.SenderPublicKey, &t.Signature).Bytes(),
+	}
 }
@@ -3,34 +3,11 @@ package core
 import (
 	"testing"
 	"bytes"
+	"github.com/terorie/go-nimiq/ed25519"
 )
 
 func TestBasicTx_Deserialize(t *testing.T) {
-	buf := []byte {
-		// Tx Format (Basic)
-		0x00,
-		// SenderPubKey
-		0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1A, 0x1B, 0x1C, 0x1D, 0x1E, 0x1F,
-		0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28, 0x29, 0x2A, 0x2B, 0x2C, 0x2D, 0x2E, 0x2F,
-		// Recipient
-		0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
-		0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
-		// Value
-		0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57,
-		// Fee
-		0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67,
-		// ValidityStartHeight
-		0x00, 0x00, 0x00, 0x00,
-		// NetworkId
-		42,
-		// Signature
-		0x70, 0x71, 0x72, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78, 0x79, 0x7A, 0x7B, 0x7C, 0x7D, 0x7E, 0x7F,
-		0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89, 0x8A, 0x8B, 0x8C, 0x8D, 0x8E, 0x8F,
-		0x90, 0x91, 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98, 0x99, 0x9A, 0x9B, 0x9C, 0x9D, 0x9E, 0x9F,
-		0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0xA7, 0xA8, 0xA9, 0xAA, 0xAB, 0xAC, 0xAD, 0xAE, 0xAF,
-	}
-
-	r := bytes.NewReader(buf)
+	r := bytes.NewReader(basicTxTestBuf)
 	txi, err := DeserializeTx(r)
 
 	if err != nil {
@@ -112,3 +89,172 @@ func TestBasicTx_Deserialize(t *testing.T) {
 		t.Fatal("Failed deserializing Signature.")
 	}
 }
+
+func TestBasicTx_Serialize(t *testing.T) {
+	txi, err := DeserializeTx(bytes.NewReader(basicTxTestBuf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if txi.SerializedSize() != len(basicTxTestBuf) {
+		t.Fatalf("Invalid serialized size.\n" +
+			"Expected: %d\n" +
+			"Actual: %d",
+			len(basicTxTestBuf), txi.SerializedSize(),
+		)
+	}
+
+	// Re-emit the deserialized tx
+	var buf bytes.Buffer
+	err = SerializeTx(&buf, txi)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), basicTxTestBuf) {
+		t.Fatalf("Serialized tx differs from input.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			basicTxTestBuf, buf.Bytes(),
+		)
+	}
+}
+
+func TestBasicTx_Hash(t *testing.T) {
+	txi, err := DeserializeTx(bytes.NewReader(basicTxTestBuf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte{
+		// Data size (0)
+		0x00, 0x00,
+		// Sender (Blake2b of SenderPubKey, truncated)
+		0x44, 0xc7, 0xd1, 0xe1, 0xb6, 0xd9, 0xd1, 0xc8, 0x87, 0x8b, 0x62, 0x59, 0x89, 0xaa, 0xb3, 0x02,
+		0x97, 0xf8, 0xf9, 0xe3,
+		// Sender type (Basic)
+		0x00,
+		// Recipient
+		0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
+		0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
+		// Recipient type (Basic)
+		0x00,
+		// Value
+		0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57,
+		// Fee
+		0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67,
+		// ValidityStartHeight
+		0x00, 0x00, 0x00, 0x00,
+		// NetworkId
+		42,
+		// Flags (None)
+		0x00,
+	}
+
+	if !bytes.Equal(txi.SerializeContent(), content) {
+		t.Fatalf("Failed serializing content.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			content, txi.SerializeContent(),
+		)
+	}
+
+	hash := Hash{
+		0x34, 0x3d, 0x07, 0x52, 0xee, 0xc3, 0x41, 0x12, 0xb3, 0x6d, 0xc0, 0x91, 0x0b, 0x54, 0xeb, 0xc7,
+		0xe3, 0x19, 0x10, 0xcb, 0x65, 0x52, 0x7e, 0xab, 0xca, 0xb3, 0xc2, 0x33, 0x96, 0xf4, 0xd4, 0xa9,
+	}
+
+	if txi.Hash() != hash {
+		t.Fatalf("Invalid tx hash.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			hash, txi.Hash(),
+		)
+	}
+}
+
+func TestBasicTx_Sign(t *testing.T) {
+	txi, err := DeserializeTx(bytes.NewReader(basicTxTestBuf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := txi.(*BasicTx)
+
+	// Garbage signature from test buffer
+	if tx.VerifySignature() {
+		t.Fatal("Accepted invalid signature as valid.")
+	}
+
+	tx.Sign(&testPrivateKey)
+
+	if !bytes.Equal(tx.SenderPublicKey[:], testPublicKey[:]) {
+		t.Fatal("Sign did not set SenderPublicKey.")
+	}
+
+	if !tx.VerifySignature() {
+		t.Fatal("Failed to verify signed tx.")
+	}
+
+	// Signature must cover the content
+	tx.Value++
+	if tx.VerifySignature() {
+		t.Fatal("Accepted signature of modified tx as valid.")
+	}
+}
+
+func TestBasicTx_IsSentBy(t *testing.T) {
+	tx := BasicTx{ SenderPublicKey: testPublicKey }
+
+	correctAddr := testPublicKey.ToAddress()
+	if !tx.IsSentBy(&correctAddr) {
+		t.Fatal("Sender address not matched.")
+	}
+
+	var otherAddr Address
+	if tx.IsSentBy(&otherAddr) {
+		t.Fatal("Wrong sender address matched.")
+	}
+}
+
+var basicTxTestBuf = []byte {
+	// Tx Format (Basic)
+	0x00,
+	// SenderPubKey
+	0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1A, 0x1B, 0x1C, 0x1D, 0x1E, 0x1F,
+	0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28, 0x29, 0x2A, 0x2B, 0x2C, 0x2D, 0x2E, 0x2F,
+	// Recipient
+	0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
+	0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
+	// Value
+	0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57,
+	// Fee
+	0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67,
+	// ValidityStartHeight
+	0x00, 0x00, 0x00, 0x00,
+	// NetworkId
+	42,
+	// Signature
+	0x70, 0x71, 0x72, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78, 0x79, 0x7A, 0x7B, 0x7C, 0x7D, 0x7E, 0x7F,
+	0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89, 0x8A, 0x8B, 0x8C, 0x8D, 0x8E, 0x8F,
+	0x90, 0x91, 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98, 0x99, 0x9A, 0x9B, 0x9C, 0x9D, 0x9E, 0x9F,
+	0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0xA7, 0xA8, 0xA9, 0xAA, 0xAB, 0xAC, 0xAD, 0xAE, 0xAF,
+}
+
+var testPrivateKey = ed25519.PrivateKey{
+	0x33, 0x71, 0x4b, 0x23, 0x98, 0x3b, 0xea, 0x98,
+	0xd0, 0x5e, 0xd4, 0x75, 0x31, 0xb6, 0x5d, 0x7b,
+	0x91, 0xc0, 0xe9, 0x3a, 0x4b, 0xb2, 0x44, 0x46,
+	0x15, 0x31, 0x37, 0x7e, 0x1e, 0x39, 0xc9, 0xe8,
+	0x75, 0xa4, 0xb9, 0xa1, 0x7b, 0x68, 0x57, 0xca,
+	0x7d, 0x17, 0xee, 0x9b, 0xcd, 0x36, 0xb3, 0x6e,
+	0x6d, 0xf5, 0x22, 0x1e, 0x5f, 0x36, 0xfa, 0x69,
+	0x73, 0xd6, 0x4d, 0x57, 0x9c, 0xd2, 0x55, 0x51,
+}
+
+var testPublicKey = PublicKey{
+	0x75, 0xa4, 0xb9, 0xa1, 0x7b, 0x68, 0x57, 0xca,
+	0x7d, 0x17, 0xee, 0x9b, 0xcd, 0x36, 0xb3, 0x6e,
+	0x6d, 0xf5, 0x22, 0x1e, 0x5f, 0x36, 0xfa, 0x69,
+	0x73, 0xd6, 0x4d, 0x57, 0x9c, 0xd2, 0x55, 0x51,
+}
@@ -1,8 +1,11 @@
 package core
 
 import (
+	bu "github.com/terorie/go-nimiq/bufferutils"
 	"io"
 	"encoding/binary"
+	"errors"
+	"math"
 )
 
 type ExtendedTx struct {
@@ -14,15 +17,52 @@ type ExtendedTx struct {
 	Value Satoshi
 	Fee Satoshi
 	ValidityStartHeight uint32
-	NetworkId uint8
-	Flags uint8
+	NetworkId NetworkId
+	Flags TxFlags
 	Proof []byte
 }
 
+// Size of the static fields of an ExtendedTx
+const extendedTxStaticSize =
+	20 + // Sender
+	1 +  // SenderType
+	20 + // Recipient
+	1 +  // RecipientType
+	8 +  // Value
+	8 +  // Fee
+	4 +  // ValidityStartHeight
+	1 +  // NetworkId
+	1    // Flags
+
+var ErrExtendedTxFieldTooLong = errors.New("extended tx data or proof longer than 65535 bytes")
+
+// Tx flags bitmap
+type TxFlags uint8
+const (
+	TxFlagContractCreation = TxFlags(1 << 0)
+	// All known flags
+	TxFlagsAll = TxFlagContractCreation
+)
+
+func (e *ExtendedTx) HasFlag(flag TxFlags) bool {
+	return e.Flags & flag != 0
+}
+
 func (_ *ExtendedTx) Format() TxFormat {
 	return TxFormatExtended
 }
 
+func (e *ExtendedTx) ToExtended() *ExtendedTx {
+	return e
+}
+
+func (e *ExtendedTx) SerializedSize() int {
+	return 1 + // Format
+		2 + len(e.Data) +
+		extendedTxStaticSize +
+		2 + len(e.Proof)
+}
+
 func (e *ExtendedTx) Deserialize(r io.Reader) error {
 	bo := binary.BigEndian
 	var err error
@@ -74,3 +114,79 @@ func (e *ExtendedTx) Deserialize(r io.Reader) error {
 	// No error
 	return nil
 }
+
+func (e *ExtendedTx) Serialize(w io.Writer) error {
+	bo := binary.BigEndian
+	var err error
+
+	// Length prefixes are uint16
+	if len(e.Data) > math.MaxUint16 || len(e.Proof) > math.MaxUint16 {
+		return ErrExtendedTxFieldTooLong
+	}
+
+	// Variable length part
+
+	// Write length of data segment
+	err = binary.Write(w, bo, uint16(len(e.Data)))
+	if err != nil { return err }
+
+	// Write data segment
+	_, err = w.Write(e.Data)
+	if err != nil { return err }
+
+	// Static length part
+
+	// Write all static fields
+	staticFields := []interface{}{
+		e.Sender[:],
+		e.SenderType,
+		e.Recipient[:],
+		e.RecipientType,
+		e.Value,
+		e.Fee,
+		e.ValidityStartHeight,
+		e.NetworkId,
+		e.Flags,
+	}
+
+	for _, field := range staticFields {
+		err = binary.Write(w, bo, field)
+		if err != nil { return err }
+	}
+
+	// Variable length part
+
+	// Write length of proof data segment
+	err = binary.Write(w, bo, uint16(len(e.Proof)))
+	if err != nil { return err }
+
+	// Write proof segment
+	_, err = w.Write(e.Proof)
+	if err != nil { return err }
+
+	// No error
+	return nil
+}
+
+func (e *ExtendedTx) SerializeContent() []byte {
+	buf := make([]byte, 2 + len(e.Data) + extendedTxStaticSize)
+	sl := bu.WriteBytes(buf)
+
+	sl.Uint16(uint16(len(e.Data)))
+	sl.WriteNext(e.Data)
+	sl.WriteNext(e.Sender[:])
+	sl.Uint8(uint8(e.SenderType))
+	sl.WriteNext(e.Recipient[:])
+	sl.Uint8(uint8(e.RecipientType))
+	sl.Uint64(uint64(e.Value))
+	sl.Uint64(uint64(e.Fee))
+	sl.Uint32(e.ValidityStartHeight)
+	sl.Uint8(uint8(e.NetworkId))
+	sl.Uint8(uint8(e.Flags))
+
+	return buf
+}
+
+func (e *ExtendedTx) Hash() Hash {
+	return Blake2bHash(e.SerializeContent())
+}
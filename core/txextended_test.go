@@ -6,43 +6,8 @@ import (
 )
 
 func TestExtendedTx_Deserialize(t *testing.T) {
-	buf := []byte {
-		// Tx format (Extended)
-		0x01,
-		// Data size (32)
-		0x00, 0x20,
-		// Data
-		0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1A, 0x1B, 0x1C, 0x1D, 0x1E, 0x1F,
-		0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28, 0x29, 0x2A, 0x2B, 0x2C, 0x2D, 0x2E, 0x2F,
-		// Sender
-		0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
-		0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
-		// Sender type (Basic)
-		0x00,
-		// Recipient
-		0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59,
-		0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69,
-		// Recipient type (Basic)
-		0x00,
-		// Value
-		0x70, 0x71, 0x72, 0x73, 0x74, 0x75, 0x76, 0x77,
-		// Fee
-		0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87,
-		// Validity start height
-		0x90, 0x91, 0x92, 0x93,
-		// Network ID (Main)
-		42,
-		// Flags (None)
-		0x00,
-		// Proof size (32)
-		0x00, 0x20,
-		// Proof
-		0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0xA7, 0xA8, 0xA9, 0xAA, 0xAB, 0xAC, 0xAD, 0xAE, 0xAF,
-		0xB0, 0xB1, 0xB2, 0xB3, 0xB4, 0xB5, 0xB6, 0xB7, 0xB8, 0xB9, 0xBA, 0xBB, 0xBC, 0xBD, 0xBE, 0xBF,
-	}
-
 	// Simulate reading from network
-	r := bytes.NewReader(buf)
+	r := bytes.NewReader(extendedTxTestBuf)
 
 	txi, err := DeserializeTx(r)
 
@@ -122,3 +87,100 @@ func TestExtendedTx_Deserialize(t *testing.T) {
 	}
 
 }
+
+func TestExtendedTx_Serialize(t *testing.T) {
+	txi, err := DeserializeTx(bytes.NewReader(extendedTxTestBuf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if txi.SerializedSize() != len(extendedTxTestBuf) {
+		t.Fatalf("Invalid serialized size.\n" +
+			"Expected: %d\n" +
+			"Actual: %d",
+			len(extendedTxTestBuf), txi.SerializedSize(),
+		)
+	}
+
+	// Re-emit the deserialized tx
+	var buf bytes.Buffer
+	err = SerializeTx(&buf, txi)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), extendedTxTestBuf) {
+		t.Fatalf("Serialized tx differs from input.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			extendedTxTestBuf, buf.Bytes(),
+		)
+	}
+}
+
+func TestExtendedTx_Hash(t *testing.T) {
+	txi, err := DeserializeTx(bytes.NewReader(extendedTxTestBuf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Content is everything except the
+	// format byte and the proof
+	content := extendedTxTestBuf[1:len(extendedTxTestBuf)-2-32]
+
+	if !bytes.Equal(txi.SerializeContent(), content) {
+		t.Fatalf("Failed serializing content.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			content, txi.SerializeContent(),
+		)
+	}
+
+	hash := Hash{
+		0x18, 0xa1, 0x81, 0x0c, 0xb9, 0x2e, 0x92, 0xc7, 0x3a, 0xe7, 0x0d, 0xdf, 0xe3, 0x4e, 0x41, 0xbe,
+		0x69, 0x98, 0x8a, 0x94, 0x84, 0x3d, 0xa4, 0x5a, 0xc3, 0xb2, 0x2e, 0xe6, 0xda, 0xe8, 0x2e, 0xdc,
+	}
+
+	if txi.Hash() != hash {
+		t.Fatalf("Invalid tx hash.\n" +
+			"Expected: hex(%x)\n" +
+			"Actual: hex(%x)",
+			hash, txi.Hash(),
+		)
+	}
+}
+
+var extendedTxTestBuf = []byte {
+	// Tx format (Extended)
+	0x01,
+	// Data size (32)
+	0x00, 0x20,
+	// Data
+	0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1A, 0x1B, 0x1C, 0x1D, 0x1E, 0x1F,
+	0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28, 0x29, 0x2A, 0x2B, 0x2C, 0x2D, 0x2E, 0x2F,
+	// Sender
+	0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
+	0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
+	// Sender type (Basic)
+	0x00,
+	// Recipient
+	0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59,
+	0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69,
+	// Recipient type (Basic)
+	0x00,
+	// Value
+	0x70, 0x71, 0x72, 0x73, 0x74, 0x75, 0x76, 0x77,
+	// Fee
+	0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87,
+	// Validity start height
+	0x90, 0x91, 0x92, 0x93,
+	// Network ID (Main)
+	42,
+	// Flags (None)
+	0x00,
+	// Proof size (32)
+	0x00, 0x20,
+	// Proof
+	0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0xA7, 0xA8, 0xA9, 0xAA, 0xAB, 0xAC, 0xAD, 0xAE, 0xAF,
+	0xB0, 0xB1, 0xB2, 0xB3, 0xB4, 0xB5, 0xB6, 0xB7, 0xB8, 0xB9, 0xBA, 0xBB, 0xBC, 0xBD, 0xBE, 0xBF,
+}
@@ -0,0 +1,192 @@
+package core
+
+import "math"
+
+// Static tx verification: Checks that don't
+// require knowledge of the account states
+
+// Max size of data attached to txs to basic accounts
+const BasicTxDataMaxSize = 64
+
+func (t *BasicTx) Verify(networkId NetworkId) error {
+	sender := t.Sender()
+	err := verifyTxCommon(&sender, &t.Recipient, t.Value, t.Fee, t.NetworkId, networkId)
+	if err != nil { return err }
+
+	if !t.VerifySignature() {
+		return ErrTx_InvalidSignature
+	}
+
+	return nil
+}
+
+func (e *ExtendedTx) Verify(networkId NetworkId) error {
+	err := verifyTxCommon(&e.Sender, &e.Recipient, e.Value, e.Fee, e.NetworkId, networkId)
+	if err != nil { return err }
+
+	if e.Flags & ^TxFlagsAll != 0 {
+		return ErrTx_InvalidFlags
+	}
+
+	// Cheap checks first, signatures last
+	err = e.verifyIncoming()
+	if err != nil { return err }
+
+	return e.verifyOutgoing()
+}
+
+func verifyTxCommon(sender, recipient *Address, value, fee Satoshi, txNetworkId, networkId NetworkId) error {
+	if txNetworkId != networkId {
+		return ErrTx_WrongNetwork
+	}
+
+	if *sender == *recipient {
+		return ErrTx_SenderIsRecipient
+	}
+
+	if value == 0 {
+		return ErrTx_ZeroValue
+	}
+
+	if value > math.MaxUint64 - fee {
+		return ErrTx_Overflow
+	}
+
+	return nil
+}
+
+// Checks the proof against the sender account type
+func (e *ExtendedTx) verifyOutgoing() error {
+	switch e.SenderType {
+	case BasicAccount:
+		// Proof must be signed by the sender address
+		proof, err := ParseSignatureProof(e.Proof)
+		if err != nil { return ErrTx_InvalidProof }
+
+		if !proof.Verify(&e.Sender, e.SerializeContent()) {
+			return ErrTx_InvalidSignature
+		}
+
+		return nil
+
+	case VestingAccount:
+		// Owner is checked against the contract state,
+		// only check that the proof is well-formed here
+		proof, err := ParseSignatureProof(e.Proof)
+		if err != nil { return ErrTx_InvalidProof }
+
+		if !proof.Verify(nil, e.SerializeContent()) {
+			return ErrTx_InvalidSignature
+		}
+
+		return nil
+
+	case HtlcAccount:
+		proof, err := ParseHtlcProof(e.Proof)
+		if err != nil { return ErrTx_InvalidProof }
+
+		return proof.Verify(e.SerializeContent())
+
+	default:
+		return ErrTx_InvalidSenderType
+	}
+}
+
+// Checks the tx against the recipient account type
+func (e *ExtendedTx) verifyIncoming() error {
+	switch e.RecipientType {
+	case BasicAccount:
+		if e.HasFlag(TxFlagContractCreation) {
+			return ErrTx_InvalidContractCreation
+		}
+
+		if len(e.Data) > BasicTxDataMaxSize {
+			return ErrTx_DataTooLong
+		}
+
+		return nil
+
+	case VestingAccount:
+		// Contracts only receive funds on creation
+		if !e.HasFlag(TxFlagContractCreation) {
+			return ErrTx_InvalidContractCreation
+		}
+
+		_, err := e.VestingCreationData()
+		if err != nil { return err }
+
+		return e.verifyContractAddress()
+
+	case HtlcAccount:
+		if !e.HasFlag(TxFlagContractCreation) {
+			return ErrTx_InvalidContractCreation
+		}
+
+		_, err := e.HtlcCreationData()
+		if err != nil { return err }
+
+		return e.verifyContractAddress()
+
+	default:
+		return ErrTx_InvalidRecipientType
+	}
+}
+
+// The recipient of a contract creation
+// must be the address of the new contract
+func (e *ExtendedTx) verifyContractAddress() error {
+	if e.Recipient != e.ContractCreationAddress() {
+		return ErrTx_InvalidContractCreation
+	}
+	return nil
+}
+
+// Error codes
+type TxError uint8
+
+const (
+	_ = TxError(iota)
+	ErrTx_WrongNetwork
+	ErrTx_SenderIsRecipient
+	ErrTx_ZeroValue
+	ErrTx_Overflow
+	ErrTx_InvalidFlags
+	ErrTx_InvalidSenderType
+	ErrTx_InvalidRecipientType
+	ErrTx_DataTooLong
+	ErrTx_InvalidProof
+	ErrTx_InvalidSignature
+	ErrTx_InvalidContractCreation
+	ErrTx_InvalidCreationData
+)
+
+func (t TxError) Error() string {
+	switch t {
+	case ErrTx_WrongNetwork:
+		return "invalid tx: wrong network id"
+	case ErrTx_SenderIsRecipient:
+		return "invalid tx: sender equals recipient"
+	case ErrTx_ZeroValue:
+		return "invalid tx: zero value"
+	case ErrTx_Overflow:
+		return "invalid tx: value + fee overflows"
+	case ErrTx_InvalidFlags:
+		return "invalid tx: unknown flags"
+	case ErrTx_InvalidSenderType:
+		return "invalid tx: invalid sender account type"
+	case ErrTx_InvalidRecipientType:
+		return "invalid tx: invalid recipient account type"
+	case ErrTx_DataTooLong:
+		return "invalid tx: data too long"
+	case ErrTx_InvalidProof:
+		return "invalid tx: malformed proof"
+	case ErrTx_InvalidSignature:
+		return "invalid tx: invalid signature"
+	case ErrTx_InvalidContractCreation:
+		return "invalid tx: invalid contract creation"
+	case ErrTx_InvalidCreationData:
+		return "invalid tx: invalid contract creation data"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,124 @@
+package core
+
+import "testing"
+
+func TestBasicTx_Verify(t *testing.T) {
+	newTx := func() *BasicTx {
+		tx := &BasicTx{
+			Recipient: Address{0x01},
+			Value: 100,
+			Fee: 1,
+			ValidityStartHeight: 1000,
+			NetworkId: 42,
+		}
+		tx.Sign(&testPrivateKey)
+		return tx
+	}
+
+	if err := newTx().Verify(42); err != nil {
+		t.Fatalf("Valid tx rejected: %s", err)
+	}
+
+	if err := newTx().Verify(1); err != ErrTx_WrongNetwork {
+		t.Fatal("Tx of other network accepted.")
+	}
+
+	tx := newTx()
+	tx.Value = 0
+	tx.Sign(&testPrivateKey)
+	if err := tx.Verify(42); err != ErrTx_ZeroValue {
+		t.Fatal("Zero value tx accepted.")
+	}
+
+	tx = newTx()
+	tx.Fee = Satoshi(^uint64(0))
+	tx.Sign(&testPrivateKey)
+	if err := tx.Verify(42); err != ErrTx_Overflow {
+		t.Fatal("Overflowing tx accepted.")
+	}
+
+	tx = newTx()
+	tx.Recipient = tx.Sender()
+	tx.Sign(&testPrivateKey)
+	if err := tx.Verify(42); err != ErrTx_SenderIsRecipient {
+		t.Fatal("Tx to sender accepted.")
+	}
+
+	tx = newTx()
+	tx.Value++
+	if err := tx.Verify(42); err != ErrTx_InvalidSignature {
+		t.Fatal("Tx with invalid signature accepted.")
+	}
+}
+
+func TestExtendedTx_Verify(t *testing.T) {
+	// An extended tx between basic accounts
+	// has the same content as the basic tx
+	basicTx := BasicTx{
+		Recipient: Address{0x01},
+		Value: 100,
+		Fee: 1,
+		ValidityStartHeight: 1000,
+		NetworkId: 42,
+	}
+	basicTx.Sign(&testPrivateKey)
+
+	newTx := func() *ExtendedTx {
+		return &ExtendedTx{
+			Data: []byte{},
+			Sender: basicTx.Sender(),
+			SenderType: BasicAccount,
+			Recipient: basicTx.Recipient,
+			RecipientType: BasicAccount,
+			Value: basicTx.Value,
+			Fee: basicTx.Fee,
+			ValidityStartHeight: basicTx.ValidityStartHeight,
+			NetworkId: basicTx.NetworkId,
+			Proof: NewSingleSigProof(&basicTx.SenderPublicKey, &basicTx.Signature).Bytes(),
+		}
+	}
+
+	if newTx().Hash() != basicTx.Hash() {
+		t.Fatal("Extended and basic tx hashes differ.")
+	}
+
+	if err := newTx().Verify(42); err != nil {
+		t.Fatalf("Valid tx rejected: %s", err)
+	}
+
+	tx := newTx()
+	tx.Sender = Address{0x02}
+	if err := tx.Verify(42); err != ErrTx_InvalidSignature {
+		t.Fatal("Proof of other sender accepted.")
+	}
+
+	tx = newTx()
+	tx.Proof = append(tx.Proof, 0x00)
+	if err := tx.Verify(42); err != ErrTx_InvalidProof {
+		t.Fatal("Overlong proof accepted.")
+	}
+
+	tx = newTx()
+	tx.Flags = 0x80
+	if err := tx.Verify(42); err != ErrTx_InvalidFlags {
+		t.Fatal("Unknown flags accepted.")
+	}
+
+	tx = newTx()
+	tx.SenderType = AccountType(3)
+	if err := tx.Verify(42); err != ErrTx_InvalidSenderType {
+		t.Fatal("Invalid sender type accepted.")
+	}
+
+	tx = newTx()
+	tx.RecipientType = AccountType(3)
+	if err := tx.Verify(42); err != ErrTx_InvalidRecipientType {
+		t.Fatal("Invalid recipient type accepted.")
+	}
+
+	tx = newTx()
+	tx.Data = make([]byte, BasicTxDataMaxSize + 1)
+	if err := tx.Verify(42); err != ErrTx_DataTooLong {
+		t.Fatal("Tx with too much data accepted.")
+	}
+}
@@ -1,3 +1,11 @@
 package core
 
+import "github.com/terorie/go-nimiq/policy"
+
+// Amount in Luna (smallest unit, 1e-5 NIM)
 type Satoshi uint64
+
+// Formats the amount in NIM
+func (s Satoshi) String() string {
+	return policy.FormatNim(uint64(s))
+}
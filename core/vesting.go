@@ -0,0 +1,176 @@
+package core
+
+import (
+	"math"
+	bu "github.com/terorie/go-nimiq/bufferutils"
+)
+
+// Vesting contract: Funds unlock in steps
+// of VestingStepAmount every VestingStepBlocks
+// blocks, starting at block VestingStart.
+type VestingContract struct {
+	Balance Satoshi
+	Owner Address
+	VestingStart uint32
+	VestingStepBlocks uint32
+	VestingStepAmount Satoshi
+	VestingTotalAmount Satoshi
+}
+
+// Serialized size without the account type
+const VestingContractSize =
+	8 +  // Balance
+	20 + // Owner
+	4 +  // VestingStart
+	4 +  // VestingStepBlocks
+	8 +  // VestingStepAmount
+	8    // VestingTotalAmount
+
+func (_ *VestingContract) Type() AccountType {
+	return VestingAccount
+}
+
+func (v *VestingContract) GetBalance() Satoshi {
+	return v.Balance
+}
+
+func (_ *VestingContract) SerializedSize() int {
+	return 1 + VestingContractSize
+}
+
+func (_ *VestingContract) IsInitial() bool {
+	return false
+}
+
+func (v *VestingContract) Deserialize(buf *[VestingContractSize]byte) {
+	sl := bu.ReadBytes(buf[:])
+
+	v.Balance = Satoshi(sl.Uint64())
+	sl.CopyNext(v.Owner[:])
+	v.VestingStart = sl.Uint32()
+	v.VestingStepBlocks = sl.Uint32()
+	v.VestingStepAmount = Satoshi(sl.Uint64())
+	v.VestingTotalAmount = Satoshi(sl.Uint64())
+}
+
+func (v *VestingContract) Serialize(buf *[VestingContractSize]byte) {
+	sl := bu.WriteBytes(buf[:])
+
+	sl.Uint64(uint64(v.Balance))
+	sl.WriteNext(v.Owner[:])
+	sl.Uint32(v.VestingStart)
+	sl.Uint32(v.VestingStepBlocks)
+	sl.Uint64(uint64(v.VestingStepAmount))
+	sl.Uint64(uint64(v.VestingTotalAmount))
+}
+
+// Amount that is still locked at the given block height.
+// The balance can't be spent below this amount.
+func (v *VestingContract) MinCap(height uint32) Satoshi {
+	// No vesting schedule, everything is unlocked
+	if v.VestingStepBlocks == 0 || v.VestingStepAmount == 0 {
+		return 0
+	}
+
+	total := uint64(v.VestingTotalAmount)
+	amount := uint64(v.VestingStepAmount)
+
+	if height < v.VestingStart {
+		// Vesting hasn't started: The reference client
+		// counts the steps until the start negatively
+		blocks := v.VestingStart - height
+		steps := uint64(blocks / v.VestingStepBlocks)
+		if blocks % v.VestingStepBlocks != 0 {
+			steps++
+		}
+
+		// Saturate instead of overflowing
+		if steps > (math.MaxUint64 - total) / amount {
+			return Satoshi(math.MaxUint64)
+		}
+		return Satoshi(total + steps * amount)
+	}
+
+	steps := uint64((height - v.VestingStart) / v.VestingStepBlocks)
+
+	// Everything vested, steps * amount > total
+	if steps > total / amount {
+		return 0
+	}
+	return Satoshi(total - steps * amount)
+}
+
+// Part of the balance that can be spent at the given block height
+func (v *VestingContract) SpendableBalance(height uint32) Satoshi {
+	minCap := v.MinCap(height)
+	if v.Balance <= minCap {
+		return 0
+	}
+	return v.Balance - minCap
+}
+
+// Checks if the contract allows the outgoing tx
+// at the given block height: The owner must have
+// signed it and the vested funds must cover it.
+func (v *VestingContract) VerifyOutgoingTx(tx *ExtendedTx, height uint32) error {
+	proof, err := ParseSignatureProof(tx.Proof)
+	if err != nil { return ErrAccount_InvalidProof }
+
+	if !proof.IsSignedBy(&v.Owner) {
+		return ErrAccount_InvalidProof
+	}
+
+	// Value + fee doesn't overflow in verified txs
+	amount := tx.Value + tx.Fee
+	if amount < tx.Value || amount > v.Balance {
+		return ErrAccount_InsufficientFunds
+	}
+
+	if v.Balance - amount < v.MinCap(height) {
+		return ErrAccount_FundsLocked
+	}
+
+	return nil
+}
+
+func (v *VestingContract) WithOutgoingTx(tx Tx, height uint32, txCache TxCache) (Account, error) {
+	e := tx.ToExtended()
+	if e.SenderType != VestingAccount {
+		return nil, ErrAccount_TypeMismatch
+	}
+
+	err := v.VerifyOutgoingTx(e, height)
+	if err != nil { return nil, err }
+
+	balance, err := outgoingBalance(v.Balance, e, height, txCache)
+	if err != nil { return nil, err }
+
+	newV := *v
+	newV.Balance = balance
+	return &newV, nil
+}
+
+func (v *VestingContract) RevertOutgoingTx(tx Tx, height uint32) (Account, error) {
+	balance, err := revertOutgoingBalance(v.Balance, tx.ToExtended())
+	if err != nil { return nil, err }
+
+	newV := *v
+	newV.Balance = balance
+	return &newV, nil
+}
+
+func (v *VestingContract) WithIncomingTx(tx Tx, height uint32) (Account, error) {
+	return contractIncomingTx()
+}
+
+func (v *VestingContract) RevertIncomingTx(tx Tx, height uint32) (Account, error) {
+	return contractIncomingTx()
+}
+
+func (v *VestingContract) WithContractCommand(tx Tx, height uint32) (Account, error) {
+	return contractWithContractCommand(v, tx)
+}
+
+func (v *VestingContract) RevertContractCommand(tx Tx, height uint32) (Account, error) {
+	return contractRevertContractCommand(v, tx)
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"testing"
+	"bytes"
+)
+
+func TestVestingContract_Serialize(t *testing.T) {
+	buf := [VestingContractSize]byte{
+		// Balance
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0xe8,
+		// Owner
+		0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
+		0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
+		// VestingStart
+		0x00, 0x00, 0x00, 0x64,
+		// VestingStepBlocks
+		0x00, 0x00, 0x00, 0x0a,
+		// VestingStepAmount
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc8,
+		// VestingTotalAmount
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0xe8,
+	}
+
+	var v VestingContract
+	v.Deserialize(&buf)
+
+	if v.Balance != 1000 || v.VestingStart != 100 || v.VestingStepBlocks != 10 ||
+		v.VestingStepAmount != 200 || v.VestingTotalAmount != 1000 {
+		t.Fatalf("Failed deserializing vesting contract: %+v", v)
+	}
+
+	var out [VestingContractSize]byte
+	v.Serialize(&out)
+
+	if !bytes.Equal(out[:], buf[:]) {
+		t.Fatalf("Failed serializing vesting contract: hex(%x)", out)
+	}
+}
+
+func TestVestingContract_MinCap(t *testing.T) {
+	v := VestingContract{
+		Balance: 1000,
+		VestingStart: 100,
+		VestingStepBlocks: 10,
+		VestingStepAmount: 200,
+		VestingTotalAmount: 1000,
+	}
+
+	caps := []struct{
+		height uint32
+		minCap Satoshi
+	}{
+		{ 0, 3000 }, // 10 steps before the start
+		{ 95, 1200 },
+		{ 100, 1000 },
+		{ 109, 1000 },
+		{ 110, 800 },
+		{ 145, 200 },
+		{ 150, 0 },
+		{ 1000000, 0 },
+	}
+
+	for _, c := range caps {
+		if minCap := v.MinCap(c.height); minCap != c.minCap {
+			t.Errorf("Invalid min cap at height %d.\n" +
+				"Expected: %d\n" +
+				"Actual: %d",
+				c.height, c.minCap, minCap,
+			)
+		}
+	}
+
+	if v.SpendableBalance(125) != 400 {
+		t.Errorf("Invalid spendable balance: %d", v.SpendableBalance(125))
+	}
+
+	// No schedule: Everything unlocked
+	v.VestingStepBlocks = 0
+	if v.MinCap(0) != 0 {
+		t.Error("Funds locked without vesting schedule.")
+	}
+}
+
+func TestVestingContract_VerifyOutgoingTx(t *testing.T) {
+	v := VestingContract{
+		Balance: 1000,
+		Owner: testPublicKey.ToAddress(),
+		VestingStart: 100,
+		VestingStepBlocks: 10,
+		VestingStepAmount: 200,
+		VestingTotalAmount: 1000,
+	}
+
+	tx := ExtendedTx{
+		Sender: Address{0x01},
+		SenderType: VestingAccount,
+		Recipient: testPublicKey.ToAddress(),
+		Value: 390,
+		Fee: 10,
+		NetworkId: 42,
+	}
+	tx.Proof = testSignatureProof(tx.SerializeContent()).Bytes()
+
+	if err := v.VerifyOutgoingTx(&tx, 130); err != nil {
+		t.Fatalf("Valid tx rejected: %s", err)
+	}
+
+	if err := v.VerifyOutgoingTx(&tx, 119); err != ErrAccount_FundsLocked {
+		t.Fatal("Tx spending locked funds accepted.")
+	}
+
+	tx.Value = 2000
+	if err := v.VerifyOutgoingTx(&tx, 200); err != ErrAccount_InsufficientFunds {
+		t.Fatal("Tx exceeding balance accepted.")
+	}
+
+	tx.Value = 390
+	v.Owner = Address{0x02}
+	if err := v.VerifyOutgoingTx(&tx, 130); err != ErrAccount_InvalidProof {
+		t.Fatal("Tx not signed by owner accepted.")
+	}
+}
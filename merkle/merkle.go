@@ -0,0 +1,91 @@
+// Package merkle implements the Blake2b Merkle trees
+// used by Nimiq for block bodies, block interlinks
+// and multisig addresses.
+package merkle
+
+import "golang.org/x/crypto/blake2b"
+
+// Value that can be a leaf of a Merkle tree
+type Leaf interface {
+	// Hash of the value as it goes into the tree
+	LeafHash() [32]byte
+}
+
+// Leaf that is already a hash (used as-is)
+type HashLeaf [32]byte
+
+func (h HashLeaf) LeafHash() [32]byte {
+	return h
+}
+
+// Leaf of raw bytes (hashed with Blake2b)
+type BytesLeaf []byte
+
+func (b BytesLeaf) LeafHash() [32]byte {
+	return blake2b.Sum256(b)
+}
+
+// Computes the root of the Merkle tree over the leaves:
+// Splits the leaves in half (left half rounded up) and
+// hashes the concatenation of both subtree roots.
+// The root of no leaves is the hash of empty data.
+func ComputeRoot(leaves []Leaf) [32]byte {
+	root, _ := compute(leafHashes(leaves), -1)
+	return root
+}
+
+// Computes the path from the leaf at index to the root
+func ComputePath(leaves []Leaf, index int) Path {
+	if index < 0 || index >= len(leaves) {
+		return nil
+	}
+	_, path := compute(leafHashes(leaves), index)
+	return path
+}
+
+func leafHashes(leaves []Leaf) [][32]byte {
+	hashes := make([][32]byte, len(leaves))
+	for i, leaf := range leaves {
+		hashes[i] = leaf.LeafHash()
+	}
+	return hashes
+}
+
+// Computes the root over hashes and collects
+// the siblings of the node at index (-1 for none)
+func compute(hashes [][32]byte, index int) (root [32]byte, path Path) {
+	switch len(hashes) {
+	case 0:
+		return blake2b.Sum256(nil), nil
+	case 1:
+		return hashes[0], nil
+	}
+
+	mid := (len(hashes) + 1) / 2
+
+	leftIndex, rightIndex := -1, -1
+	if index >= 0 && index < mid {
+		leftIndex = index
+	} else if index >= mid {
+		rightIndex = index - mid
+	}
+
+	left, leftPath := compute(hashes[:mid], leftIndex)
+	right, rightPath := compute(hashes[mid:], rightIndex)
+
+	// Path goes from the leaf up to the root
+	if leftIndex >= 0 {
+		path = append(leftPath, PathNode{ Hash: right, Left: false })
+	} else if rightIndex >= 0 {
+		path = append(rightPath, PathNode{ Hash: left, Left: true })
+	}
+
+	return hashPair(&left, &right), path
+}
+
+func hashPair(left, right *[32]byte) [32]byte {
+	var concat [64]byte
+	copy(concat[:32], left[:])
+	copy(concat[32:], right[:])
+	return blake2b.Sum256(concat[:])
+}
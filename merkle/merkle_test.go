@@ -0,0 +1,80 @@
+package merkle
+
+import (
+	"testing"
+	"bytes"
+	"golang.org/x/crypto/blake2b"
+)
+
+func testLeaves(n int) []Leaf {
+	leaves := make([]Leaf, n)
+	for i := range leaves {
+		leaves[i] = BytesLeaf{byte(i)}
+	}
+	return leaves
+}
+
+func TestComputeRoot(t *testing.T) {
+	if ComputeRoot(nil) != blake2b.Sum256(nil) {
+		t.Fatal("Invalid root of empty tree")
+	}
+
+	leaves := testLeaves(3)
+	if ComputeRoot(leaves[:1]) != leaves[0].LeafHash() {
+		t.Fatal("Root of single leaf is not the leaf hash")
+	}
+
+	// Left half is rounded up: H(H(l0, l1), l2)
+	h0, h1, h2 := leaves[0].LeafHash(), leaves[1].LeafHash(), leaves[2].LeafHash()
+	left := hashPair(&h0, &h1)
+	if ComputeRoot(leaves) != hashPair(&left, &h2) {
+		t.Fatal("Invalid root of three leaves")
+	}
+
+	if ComputeRoot([]Leaf{ HashLeaf(h0) }) != h0 {
+		t.Fatal("Hash leaf got hashed again")
+	}
+}
+
+func TestComputePath(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		leaves := testLeaves(n)
+		root := ComputeRoot(leaves)
+		for i := range leaves {
+			path := ComputePath(leaves, i)
+			if path.ComputeRoot(leaves[i]) != root {
+				t.Fatalf("Invalid path to leaf %d of %d", i, n)
+			}
+		}
+	}
+
+	if ComputePath(testLeaves(2), 2) != nil {
+		t.Fatal("Path to leaf out of range")
+	}
+}
+
+func TestPath_Serialize(t *testing.T) {
+	leaves := testLeaves(5)
+	path := ComputePath(leaves, 3)
+
+	var buf bytes.Buffer
+	if err := path.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != path.SerializedSize() || buf.Len() != 1 + 1 + 2 * 32 {
+		t.Fatalf("Invalid serialized size: %d", buf.Len())
+	}
+
+	// Leaf 3 of 5: left of leaf 4, right of leaves 0..2
+	if raw := buf.Bytes(); raw[0] != 2 || raw[1] != 0x40 {
+		t.Fatalf("Invalid header: %x", raw[:2])
+	}
+
+	var path2 Path
+	if err := path2.Deserialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if path2.ComputeRoot(leaves[3]) != ComputeRoot(leaves) {
+		t.Fatal("Path changed after serialization")
+	}
+}
@@ -0,0 +1,82 @@
+package merkle
+
+import (
+	"io"
+	"errors"
+	"encoding/binary"
+)
+
+// A node on the path from a leaf to the Merkle root.
+// Left is set if Hash is the left sibling.
+type PathNode struct {
+	Hash [32]byte
+	Left bool
+}
+
+// Sibling hashes from the leaf up to the root
+type Path []PathNode
+
+var ErrPathTooLong = errors.New("merkle path longer than 255 nodes")
+
+// Computes the root of the Merkle tree
+// that contains the given leaf
+func (m Path) ComputeRoot(leaf Leaf) [32]byte {
+	root := leaf.LeafHash()
+	for _, node := range m {
+		if node.Left {
+			root = hashPair(&node.Hash, &root)
+		} else {
+			root = hashPair(&root, &node.Hash)
+		}
+	}
+	return root
+}
+
+func (m Path) SerializedSize() int {
+	return 1 + // Count
+		(len(m) + 7) / 8 + // Left bits
+		len(m) * 32 // Hashes
+}
+
+// Wire format: count (uint8), left bits
+// (one bit per node, MSB first), hashes
+func (m Path) Serialize(w io.Writer) error {
+	if len(m) > 0xFF {
+		return ErrPathTooLong
+	}
+
+	buf := make([]byte, m.SerializedSize())
+	buf[0] = uint8(len(m))
+
+	leftBits := buf[1:1 + (len(m) + 7) / 8]
+	hashes := buf[1 + len(leftBits):]
+	for i, node := range m {
+		if node.Left {
+			leftBits[i / 8] |= 0x80 >> uint(i % 8)
+		}
+		copy(hashes[i*32:], node.Hash[:])
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
+
+func (m *Path) Deserialize(r io.Reader) error {
+	var count uint8
+	err := binary.Read(r, binary.BigEndian, &count)
+	if err != nil { return err }
+
+	leftBits := make([]byte, (int(count) + 7) / 8)
+	_, err = io.ReadFull(r, leftBits)
+	if err != nil { return err }
+
+	nodes := make(Path, count)
+	for i := range nodes {
+		_, err = io.ReadFull(r, nodes[i].Hash[:])
+		if err != nil { return err }
+		nodes[i].Left = leftBits[i / 8] & (0x80 >> uint(i % 8)) != 0
+	}
+
+	*m = nodes
+	return nil
+}
@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"strconv"
+	"math/bits"
+)
+
+// Decimal places of NIM amounts
+const CoinDecimals = 5
+
+var (
+	ErrLunaOverflow = errors.New("luna amount overflows")
+	ErrLunaUnderflow = errors.New("luna amount is negative")
+	ErrInvalidNim = errors.New("invalid NIM amount")
+)
+
+// Formats a Luna amount as NIM with all decimals ("1.50000")
+func FormatNim(luna uint64) string {
+	whole := luna / LunasPerCoin
+	frac := luna % LunasPerCoin
+
+	fracStr := strconv.FormatUint(frac, 10)
+	return strconv.FormatUint(whole, 10) + "." +
+		strings.Repeat("0", CoinDecimals - len(fracStr)) + fracStr
+}
+
+// Parses a NIM amount ("12", "0.5", "1.00001") to Luna.
+// More than 5 decimals and negative amounts are rejected.
+func ParseNim(s string) (uint64, error) {
+	wholeStr, fracStr := s, ""
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		wholeStr, fracStr = s[:dot], s[dot+1:]
+	}
+
+	if len(fracStr) > CoinDecimals ||
+		(wholeStr == "" && fracStr == "") ||
+		!isDigits(wholeStr) || !isDigits(fracStr) {
+		return 0, ErrInvalidNim
+	}
+
+	var whole, frac uint64
+	var err error
+	if wholeStr != "" {
+		whole, err = strconv.ParseUint(wholeStr, 10, 64)
+		if err != nil { return 0, ErrLunaOverflow }
+	}
+	if fracStr != "" {
+		fracStr += strings.Repeat("0", CoinDecimals - len(fracStr))
+		frac, err = strconv.ParseUint(fracStr, 10, 64)
+		if err != nil { return 0, ErrInvalidNim }
+	}
+
+	hi, luna := bits.Mul64(whole, LunasPerCoin)
+	if hi != 0 {
+		return 0, ErrLunaOverflow
+	}
+	return AddLuna(luna, frac)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Overflow-checked addition
+func AddLuna(a, b uint64) (uint64, error) {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return 0, ErrLunaOverflow
+	}
+	return sum, nil
+}
+
+// Underflow-checked subtraction
+func SubLuna(a, b uint64) (uint64, error) {
+	diff, borrow := bits.Sub64(a, b, 0)
+	if borrow != 0 {
+		return 0, ErrLunaUnderflow
+	}
+	return diff, nil
+}
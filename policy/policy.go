@@ -0,0 +1,25 @@
+// Package policy holds the consensus constants
+// of the Nimiq network and the supply curve.
+package policy
+
+import "math/big"
+
+const (
+	// Target time between blocks in seconds
+	BlockTime = 60
+	// Max size of a serialized block in bytes
+	BlockSizeMax = 100000
+	// Number of blocks the difficulty is adjusted over
+	DifficultyBlockWindow = 120
+	// Max factor the difficulty can change by per window
+	DifficultyMaxAdjustmentFactor = 2
+	// Number of blocks a tx is valid for
+	// after its validity start height
+	TransactionValidityWindow = 120
+)
+
+// Highest allowed target (lowest difficulty): 2^240
+var BlockTargetMax = new(big.Int).Lsh(big.NewInt(1), 240)
+
+// TargetHeight(BlockTargetMax)
+const BlockTargetMaxHeight = 240
@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"testing"
+	"math"
+)
+
+func TestBlockReward(t *testing.T) {
+	// (TotalSupply - InitialSupply) / 2^22
+	if r := BlockReward(1); r != 440597534 {
+		t.Fatalf("Invalid reward of block 1: %d", r)
+	}
+	if BlockReward(0) != 0 {
+		t.Fatal("Reward before genesis")
+	}
+
+	// Supply grows by the rewards
+	for _, height := range []uint32{ 1, 4999, 5000, 5001, 12345 } {
+		if SupplyAfter(height) != SupplyAfter(height - 1) + BlockReward(height) {
+			t.Fatalf("Supply mismatch at %d", height)
+		}
+	}
+
+	if r := BlockReward(EmissionTailStart); r != EmissionTailReward {
+		t.Fatalf("Invalid tail reward: %d", r)
+	}
+}
+
+func TestSupplyBetween_MaxHeight(t *testing.T) {
+	supply := uint64(TotalSupply - 10 * EmissionTailReward)
+	if s := supplyBetween(supply, math.MaxUint32 - 2, math.MaxUint32); s != supply + 2 * EmissionTailReward {
+		t.Fatalf("Invalid supply at max height: %d", s)
+	}
+	if supplyBetween(supply, math.MaxUint32, math.MaxUint32) != supply {
+		t.Fatal("Empty range changed supply")
+	}
+}
+
+func TestFormatNim(t *testing.T) {
+	cases := map[uint64]string{
+		0: "0.00000",
+		1: "0.00001",
+		150000: "1.50000",
+		TotalSupply: "21000000000.00000",
+	}
+	for luna, nim := range cases {
+		if s := FormatNim(luna); s != nim {
+			t.Fatalf("Invalid format of %d: %s", luna, s)
+		}
+	}
+}
+
+func TestParseNim(t *testing.T) {
+	cases := map[string]uint64{
+		"0": 0,
+		"12": 1200000,
+		"0.5": 50000,
+		".00001": 1,
+		"1.": 100000,
+		"21000000000.00000": TotalSupply,
+	}
+	for nim, luna := range cases {
+		if l, err := ParseNim(nim); err != nil || l != luna {
+			t.Fatalf("Invalid parse of %s: %d (%v)", nim, l, err)
+		}
+	}
+
+	for _, nim := range []string{ "", ".", "-1", "1.000001", "1e5", "1,5" } {
+		if _, err := ParseNim(nim); err != ErrInvalidNim {
+			t.Fatalf("Invalid amount accepted: %s", nim)
+		}
+	}
+	if _, err := ParseNim("184467440737096"); err != ErrLunaOverflow {
+		t.Fatal("Overflow accepted")
+	}
+}
+
+func TestAddLuna(t *testing.T) {
+	if _, err := AddLuna(^uint64(0), 1); err != ErrLunaOverflow {
+		t.Fatal("Overflow accepted")
+	}
+	if _, err := SubLuna(1, 2); err != ErrLunaUnderflow {
+		t.Fatal("Underflow accepted")
+	}
+}
@@ -0,0 +1,75 @@
+package policy
+
+import "sync"
+
+const (
+	// Smallest units (Luna) per NIM
+	LunasPerCoin = 100000
+	// Supply limit in Luna
+	TotalSupply = 2100000000000000
+	// Supply at the genesis block in Luna
+	InitialSupply = 252000000000000
+	// Block reward is the remaining supply
+	// divided by the emission speed
+	EmissionSpeed = 1 << 22
+	// Height at which the fixed tail emission starts
+	EmissionTailStart = 48692960
+	// Block reward of the tail emission in Luna
+	EmissionTailReward = 4000
+)
+
+// Supply is cached every supplyCacheInterval blocks
+const supplyCacheInterval = 5000
+
+var supplyCache = []uint64{ InitialSupply }
+var supplyCacheMutex sync.Mutex
+
+// Reward of the block at height given the supply before it
+func blockRewardAt(currentSupply uint64, height uint32) uint64 {
+	if height == 0 {
+		return 0
+	}
+
+	remaining := TotalSupply - currentSupply
+	if height >= EmissionTailStart && remaining >= EmissionTailReward {
+		return EmissionTailReward
+	}
+
+	return remaining / EmissionSpeed
+}
+
+// Total supply in Luna after the block at height
+func SupplyAfter(height uint32) uint64 {
+	supplyCacheMutex.Lock()
+	defer supplyCacheMutex.Unlock()
+
+	// Fill cache up to the requested height
+	cacheIndex := int(height / supplyCacheInterval)
+	for len(supplyCache) <= cacheIndex {
+		start := uint32(len(supplyCache) - 1) * supplyCacheInterval
+		supply := supplyCache[len(supplyCache) - 1]
+		supply = supplyBetween(supply, start, start + supplyCacheInterval)
+		supplyCache = append(supplyCache, supply)
+	}
+
+	start := uint32(cacheIndex) * supplyCacheInterval
+	return supplyBetween(supplyCache[cacheIndex], start, height)
+}
+
+// Supply after block end given the supply after block start
+func supplyBetween(supply uint64, start, end uint32) uint64 {
+	// uint64 counter: end can be math.MaxUint32
+	for i := uint64(start) + 1; i <= uint64(end); i++ {
+		supply += blockRewardAt(supply, uint32(i))
+	}
+	return supply
+}
+
+// Reward of the block at height in Luna
+// (excluding tx fees)
+func BlockReward(height uint32) uint64 {
+	if height == 0 {
+		return 0
+	}
+	return blockRewardAt(SupplyAfter(height - 1), height)
+}
@@ -0,0 +1,404 @@
+package storage
+
+import (
+	"os"
+	"io"
+	"sync"
+	"bufio"
+	"errors"
+	"hash/crc32"
+	"path/filepath"
+	"encoding/binary"
+)
+
+// Store backed by an append-only log file.
+// Every batch is one record, the state is kept in memory
+// and rebuilt from the log on open. A partially written
+// record at the end (crash during write) is discarded,
+// damaged records before it fail the open.
+// Write compacts the log when it outgrows the state.
+type FileDB struct {
+	mutex sync.RWMutex
+	path string
+	file *os.File
+	data map[string][]byte
+	// End of the last complete record
+	size int64
+	// Size of the ops holding the current state
+	liveSize int64
+	// Set if a failed write could not be cut off,
+	// cleared by a successful compaction
+	err error
+}
+
+// Record: payload length (uint32), CRC-32 of payload (uint32), payload
+// Payload: op count (uint32), ops
+// Op: type (uint8), key length (uint32), key,
+//     for puts: value length (uint32), value
+const recordHeaderSize = 8
+
+const (
+	opPut    = uint8(0)
+	opDelete = uint8(1)
+)
+
+// Write compacts the log once it is larger than compactMinSize
+// and compactRatio times the size of the current state
+const (
+	compactMinSize = 1 << 20
+	compactRatio   = 4
+)
+
+var errCorruptRecord = errors.New("corrupt storage record")
+
+// A record before the end of the log is damaged
+var ErrCorrupt = errors.New("storage log corrupted")
+
+// Opens or creates the store at path
+func OpenFileDB(path string) (*FileDB, error) {
+	file, err := os.OpenFile(path, os.O_RDWR | os.O_CREATE, 0644)
+	if err != nil { return nil, err }
+
+	f := &FileDB{
+		path: path,
+		file: file,
+		data: make(map[string][]byte),
+	}
+
+	err = f.recover()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Replays the log and cuts off a torn last record.
+// Damaged records before the last one are reported as ErrCorrupt.
+func (f *FileDB) recover() error {
+	info, err := f.file.Stat()
+	if err != nil { return err }
+	fileSize := info.Size()
+
+	r := bufio.NewReader(f.file)
+	for {
+		b, n, err := readRecord(r, fileSize - f.size)
+		if err == io.EOF {
+			break
+		} else if err == io.ErrUnexpectedEOF {
+			// Incomplete write: Drop the rest
+			break
+		} else if err == errCorruptRecord {
+			// Damaged last record: Incomplete write
+			if f.size + n == fileSize {
+				break
+			}
+			return ErrCorrupt
+		} else if err != nil {
+			return err
+		}
+
+		f.apply(b)
+		f.size += n
+	}
+
+	return f.truncate()
+}
+
+// Cuts the log off after the last complete record
+func (f *FileDB) truncate() error {
+	err := f.file.Truncate(f.size)
+	if err != nil { return err }
+
+	_, err = f.file.Seek(f.size, io.SeekStart)
+	return err
+}
+
+// Applies the batch to the state and tracks its size
+func (f *FileDB) apply(b *Batch) {
+	for _, op := range b.ops {
+		if old, ok := f.data[string(op.key)]; ok {
+			f.liveSize -= opSize(op.key, old)
+		}
+		if op.value != nil {
+			f.liveSize += opSize(op.key, op.value)
+		}
+	}
+	applyBatch(f.data, b)
+}
+
+func opSize(key, value []byte) int64 {
+	return int64(1 + 4 + len(key) + 4 + len(value))
+}
+
+// Reads the next record of the log with remaining bytes left.
+// Returns the record size with errCorruptRecord.
+func readRecord(r io.Reader, remaining int64) (*Batch, int64, error) {
+	var header [recordHeaderSize]byte
+	n, err := io.ReadFull(r, header[:])
+	if n == 0 && err == io.EOF {
+		return nil, 0, io.EOF
+	} else if err != nil {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+
+	// Record must end within the file
+	length := int64(binary.BigEndian.Uint32(header[0:4]))
+	if length > remaining - recordHeaderSize {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	size := recordHeaderSize + length
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(r, payload)
+	if err != nil {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, size, errCorruptRecord
+	}
+
+	b, err := decodeBatch(payload)
+	if err != nil { return nil, size, err }
+
+	return b, size, nil
+}
+
+func encodeRecord(b *Batch) []byte {
+	size := 4
+	for _, op := range b.ops {
+		size += 1 + 4 + len(op.key)
+		if op.value != nil {
+			size += 4 + len(op.value)
+		}
+	}
+
+	buf := make([]byte, recordHeaderSize, recordHeaderSize + size)
+	buf = appendUint32(buf, uint32(len(b.ops)))
+	for _, op := range b.ops {
+		if op.value != nil {
+			buf = append(buf, opPut)
+		} else {
+			buf = append(buf, opDelete)
+		}
+		buf = appendUint32(buf, uint32(len(op.key)))
+		buf = append(buf, op.key...)
+		if op.value != nil {
+			buf = appendUint32(buf, uint32(len(op.value)))
+			buf = append(buf, op.value...)
+		}
+	}
+
+	payload := buf[recordHeaderSize:]
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	return buf
+}
+
+func decodeBatch(payload []byte) (*Batch, error) {
+	readUint32 := func() (uint32, bool) {
+		if len(payload) < 4 {
+			return 0, false
+		}
+		v := binary.BigEndian.Uint32(payload)
+		payload = payload[4:]
+		return v, true
+	}
+	readBytes := func() ([]byte, bool) {
+		length, ok := readUint32()
+		if !ok || uint32(len(payload)) < length {
+			return nil, false
+		}
+		v := payload[:length:length]
+		payload = payload[length:]
+		return v, true
+	}
+
+	count, ok := readUint32()
+	if !ok { return nil, errCorruptRecord }
+
+	b := new(Batch)
+	for i := uint32(0); i < count; i++ {
+		if len(payload) < 1 {
+			return nil, errCorruptRecord
+		}
+		opType := payload[0]
+		payload = payload[1:]
+
+		key, ok := readBytes()
+		if !ok { return nil, errCorruptRecord }
+
+		switch opType {
+		case opPut:
+			value, ok := readBytes()
+			if !ok { return nil, errCorruptRecord }
+			b.ops = append(b.ops, batchOp{ key, value })
+		case opDelete:
+			b.ops = append(b.ops, batchOp{ key, nil })
+		default:
+			return nil, errCorruptRecord
+		}
+	}
+
+	if len(payload) != 0 {
+		return nil, errCorruptRecord
+	}
+	return b, nil
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func (f *FileDB) Get(key []byte) ([]byte, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if f.file == nil {
+		return nil, ErrClosed
+	}
+	return getKey(f.data, key)
+}
+
+func (f *FileDB) Iterate(prefix []byte, fn func(key, value []byte) bool) error {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if f.file == nil {
+		return ErrClosed
+	}
+	iterateKeys(f.data, prefix, fn)
+	return nil
+}
+
+// Appends the batch to the log and syncs it to disk
+func (f *FileDB) Write(b *Batch) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return ErrClosed
+	}
+	if f.err != nil {
+		return f.err
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+
+	record := encodeRecord(b)
+	_, err := f.file.Write(record)
+	if err == nil {
+		err = f.file.Sync()
+	}
+	if err != nil {
+		// Cut off the partial record. If that fails too,
+		// the log can't be appended to until compacted.
+		if truncErr := f.truncate(); truncErr != nil {
+			f.err = truncErr
+		}
+		return err
+	}
+
+	f.size += int64(len(record))
+	f.apply(b)
+
+	// The batch is stored already, a failed
+	// compaction is retried on the next write
+	if f.size > compactMinSize && f.size > compactRatio * (recordHeaderSize + 4 + f.liveSize) {
+		_ = f.compact()
+	}
+	return nil
+}
+
+func (f *FileDB) Snapshot() (Reader, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if f.file == nil {
+		return nil, ErrClosed
+	}
+
+	data := make(map[string][]byte, len(f.data))
+	for k, v := range f.data {
+		data[k] = v
+	}
+	return snapshot(data), nil
+}
+
+// Rewrites the log with only the current state.
+// The old log is replaced atomically.
+func (f *FileDB) Compact() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return ErrClosed
+	}
+	return f.compact()
+}
+
+func (f *FileDB) compact() error {
+	b := new(Batch)
+	iterateKeys(f.data, nil, func(key, value []byte) bool {
+		b.ops = append(b.ops, batchOp{ key, value })
+		return true
+	})
+
+	tmpPath := f.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR | os.O_CREATE | os.O_TRUNC, 0644)
+	if err != nil { return err }
+
+	var size int64
+	if b.Len() > 0 {
+		record := encodeRecord(b)
+		_, err = tmp.Write(record)
+		size = int64(len(record))
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, f.path)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	f.file.Close()
+	f.file = tmp
+	f.size = size
+	f.err = nil
+
+	// Persist the rename
+	return syncDir(filepath.Dir(f.path))
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil { return err }
+	err = dir.Sync()
+	if closeErr := dir.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (f *FileDB) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	f.data = nil
+	return err
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"strings"
+)
+
+// In-memory store (for tests and caches)
+type MemoryDB struct {
+	mutex sync.RWMutex
+	data map[string][]byte
+	closed bool
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{ data: make(map[string][]byte) }
+}
+
+func (m *MemoryDB) Get(key []byte) ([]byte, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.closed {
+		return nil, ErrClosed
+	}
+	return getKey(m.data, key)
+}
+
+func (m *MemoryDB) Iterate(prefix []byte, fn func(key, value []byte) bool) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	iterateKeys(m.data, prefix, fn)
+	return nil
+}
+
+func (m *MemoryDB) Write(b *Batch) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	applyBatch(m.data, b)
+	return nil
+}
+
+func (m *MemoryDB) Snapshot() (Reader, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.closed {
+		return nil, ErrClosed
+	}
+
+	// Values are never modified in place,
+	// copying the map is enough
+	data := make(map[string][]byte, len(m.data))
+	for k, v := range m.data {
+		data[k] = v
+	}
+	return snapshot(data), nil
+}
+
+func (m *MemoryDB) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.closed = true
+	m.data = nil
+	return nil
+}
+
+// Immutable copy of a store
+type snapshot map[string][]byte
+
+func (s snapshot) Get(key []byte) ([]byte, error) {
+	return getKey(s, key)
+}
+
+func (s snapshot) Iterate(prefix []byte, fn func(key, value []byte) bool) error {
+	iterateKeys(s, prefix, fn)
+	return nil
+}
+
+func getKey(data map[string][]byte, key []byte) ([]byte, error) {
+	value, ok := data[string(key)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+func iterateKeys(data map[string][]byte, prefix []byte, fn func(key, value []byte) bool) {
+	var keys []string
+	for k := range data {
+		if strings.HasPrefix(k, string(prefix)) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !fn([]byte(k), data[k]) {
+			return
+		}
+	}
+}
+
+func applyBatch(data map[string][]byte, b *Batch) {
+	for _, op := range b.ops {
+		if op.value != nil {
+			data[string(op.key)] = op.value
+		} else {
+			delete(data, string(op.key))
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package storage defines the key-value store used
+// for chain and accounts data, with an in-memory and
+// an append-only file engine.
+package storage
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("key not found")
+	ErrClosed = errors.New("storage closed")
+)
+
+// Read access to a store or snapshot
+type Reader interface {
+	// Returns ErrNotFound if the key doesn't exist
+	Get(key []byte) ([]byte, error)
+	// Calls fn for all keys with prefix in ascending order
+	// until it returns false. The slices must not be modified.
+	Iterate(prefix []byte, fn func(key, value []byte) bool) error
+}
+
+// Key-value store
+type DB interface {
+	Reader
+	// Applies all changes of the batch or none
+	Write(b *Batch) error
+	// Read-only view of the current state
+	// that is unaffected by later writes
+	Snapshot() (Reader, error)
+	Close() error
+}
+
+// Changes to write atomically
+type Batch struct {
+	ops []batchOp
+}
+
+type batchOp struct {
+	key []byte
+	// nil for deletes
+	value []byte
+}
+
+// Sets key to value
+func (b *Batch) Put(key, value []byte) {
+	if value == nil {
+		value = []byte{}
+	}
+	b.ops = append(b.ops, batchOp{ copyBytes(key), copyBytes(value) })
+}
+
+func (b *Batch) Delete(key []byte) {
+	b.ops = append(b.ops, batchOp{ copyBytes(key), nil })
+}
+
+// Number of changes
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+func (b *Batch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"path/filepath"
+)
+
+// Tests the behaviour shared by all engines
+func testDB(t *testing.T, db DB) {
+	if _, err := db.Get([]byte("a")); err != ErrNotFound {
+		t.Fatal("Missing key found")
+	}
+
+	b := new(Batch)
+	b.Put([]byte("block/2"), []byte("two"))
+	b.Put([]byte("block/1"), []byte("one"))
+	b.Put([]byte("accounts/x"), []byte("x"))
+	b.Put([]byte("tmp"), nil)
+	b.Delete([]byte("tmp"))
+	if err := db.Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := db.Get([]byte("block/1")); err != nil || string(v) != "one" {
+		t.Fatal("Invalid value")
+	}
+	if _, err := db.Get([]byte("tmp")); err != ErrNotFound {
+		t.Fatal("Deleted key found")
+	}
+
+	snap, err := db.Snapshot()
+	if err != nil { t.Fatal(err) }
+
+	b.Reset()
+	b.Put([]byte("block/3"), []byte("three"))
+	b.Delete([]byte("block/1"))
+	if err := db.Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	db.Iterate([]byte("block/"), func(key, value []byte) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	if len(keys) != 2 || keys[0] != "block/2" || keys[1] != "block/3" {
+		t.Fatalf("Invalid iteration: %v", keys)
+	}
+
+	// Snapshot is unaffected by the write
+	if v, err := snap.Get([]byte("block/1")); err != nil || string(v) != "one" {
+		t.Fatal("Snapshot changed")
+	}
+	if _, err := snap.Get([]byte("block/3")); err != ErrNotFound {
+		t.Fatal("Snapshot changed")
+	}
+
+	// Stop iteration early
+	count := 0
+	snap.Iterate(nil, func(key, value []byte) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Fatal("Iteration not stopped")
+	}
+}
+
+func TestMemoryDB(t *testing.T) {
+	db := NewMemoryDB()
+	testDB(t, db)
+	db.Close()
+	if _, err := db.Get([]byte("block/2")); err != ErrClosed {
+		t.Fatal("Closed DB used")
+	}
+}
+
+func TestFileDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+	testDB(t, db)
+	db.Close()
+
+	// State is restored on open
+	db, err = OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+	if v, err := db.Get([]byte("block/3")); err != nil || string(v) != "three" {
+		t.Fatal("State not restored")
+	}
+	if _, err := db.Get([]byte("block/1")); err != ErrNotFound {
+		t.Fatal("Deleted key restored")
+	}
+
+	if err := db.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	b := new(Batch)
+	b.Put([]byte("block/4"), []byte("four"))
+	if err := db.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	var count int
+	db.Iterate(nil, func(key, value []byte) bool {
+		count++
+		return true
+	})
+	if count != 4 {
+		t.Fatalf("Invalid key count after compaction: %d", count)
+	}
+}
+
+func TestFileDB_Recover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+
+	b := new(Batch)
+	b.Put([]byte("a"), []byte("1"))
+	db.Write(b)
+	b.Reset()
+	b.Put([]byte("b"), []byte("2"))
+	b.Put([]byte("c"), []byte("3"))
+	db.Write(b)
+	db.Close()
+
+	// Crash during the second write
+	info, _ := os.Stat(path)
+	if err := os.Truncate(path, info.Size() - 3); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+	if _, err := db.Get([]byte("a")); err != nil {
+		t.Fatal("Complete batch lost")
+	}
+	if _, err := db.Get([]byte("b")); err != ErrNotFound {
+		t.Fatal("Partial batch applied")
+	}
+
+	// Log continues after the last complete batch
+	b.Reset()
+	b.Put([]byte("d"), []byte("4"))
+	db.Write(b)
+	db.Close()
+
+	db, err = OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	if _, err := db.Get([]byte("d")); err != nil {
+		t.Fatal("Write after recovery lost")
+	}
+}
+
+func TestFileDB_RecoverCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+
+	b := new(Batch)
+	b.Put([]byte("a"), []byte("1"))
+	db.Write(b)
+	db.Write(b)
+	db.Close()
+
+	// Damaged payload of the first record
+	raw, err := os.ReadFile(path)
+	if err != nil { t.Fatal(err) }
+	info, _ := os.Stat(path)
+	raw[recordHeaderSize] ^= 0xff
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenFileDB(path); err != ErrCorrupt {
+		t.Fatal("Corrupt log opened")
+	}
+	if info2, _ := os.Stat(path); info2.Size() != info.Size() {
+		t.Fatal("Corrupt log truncated")
+	}
+
+	// Damaged last record is a torn write
+	raw[recordHeaderSize] ^= 0xff
+	raw[len(raw) - 1] ^= 0xff
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+	db, err = OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	if info2, _ := os.Stat(path); info2.Size() != info.Size() / 2 {
+		t.Fatal("Damaged last record not truncated")
+	}
+}
+
+func TestFileDB_RecoverLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+
+	b := new(Batch)
+	b.Put([]byte("a"), []byte("1"))
+	db.Write(b)
+	db.Close()
+	info, _ := os.Stat(path)
+
+	// Torn record claiming a huge length
+	file, err := os.OpenFile(path, os.O_WRONLY | os.O_APPEND, 0644)
+	if err != nil { t.Fatal(err) }
+	file.Write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+	file.Close()
+
+	db, err = OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	if _, err := db.Get([]byte("a")); err != nil {
+		t.Fatal("Complete batch lost")
+	}
+	if info2, _ := os.Stat(path); info2.Size() != info.Size() {
+		t.Fatal("Torn record not truncated")
+	}
+}
+
+func TestFileDB_AutoCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := OpenFileDB(path)
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+
+	// Overwrite the same key until the log would be 4 MiB
+	value := make([]byte, 64 << 10)
+	b := new(Batch)
+	for i := 0; i < 64; i++ {
+		value[0] = byte(i)
+		b.Reset()
+		b.Put([]byte("key"), value)
+		if err := db.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info, _ := os.Stat(path)
+	if info.Size() > compactMinSize + int64(len(value)) * 2 {
+		t.Fatalf("Log not compacted: %d bytes", info.Size())
+	}
+	if v, err := db.Get([]byte("key")); err != nil || v[0] != 63 {
+		t.Fatal("Latest value lost")
+	}
+}